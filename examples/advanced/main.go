@@ -139,17 +139,18 @@ func main() {
 	}
 
 	// Alternate information
-	if flightPlan.Alternate.ICAO != "" {
-		fmt.Printf("\nAlternate: %s (%s)\n", flightPlan.Alternate.Name, flightPlan.Alternate.ICAO)
+	for _, alternate := range flightPlan.Alternates {
+		fmt.Printf("\nAlternate: %s (%s)\n", alternate.Name, alternate.ICAO)
 		fmt.Printf("Distance: %s nm, Bearing: %s°\n",
-			flightPlan.Alternate.Distance, flightPlan.Alternate.Bearing)
+			alternate.Distance, alternate.Bearing)
 		fmt.Printf("Fuel Required: %s %s\n",
-			flightPlan.Alternate.FuelRequired, flightPlan.General.Units)
+			alternate.FuelRequired, flightPlan.General.Units)
 	}
 
 	// Navigation log summary
-	if flightPlan.NavLog != nil {
-		fmt.Printf("\nNavigation Log: Available (structure may vary)\n")
+	if fixes := flightPlan.NavLogFixes(); len(fixes) > 0 {
+		fmt.Printf("\nNavigation Log: %d fixes (%s → %s)\n",
+			len(fixes), fixes[0].Ident, fixes[len(fixes)-1].Ident)
 	}
 
 	// File links
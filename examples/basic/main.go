@@ -95,8 +95,12 @@ func main() {
 	fmt.Printf("Flight Time: %s\n", flightPlan.Times.FlightTime)
 
 	// Show first few navigation fixes
-	if flightPlan.NavLog != nil {
-		fmt.Println("\nNavigation log available (structure may vary)")
+	fixes := flightPlan.NavLogFixes()
+	for i := 0; i < len(fixes) && i < 5; i++ {
+		if i == 0 {
+			fmt.Println("\nFirst navigation fixes:")
+		}
+		fmt.Printf("  %s (%s) FL%03d\n", fixes[i].Ident, fixes[i].Type, fixes[i].Altitude/100)
 	}
 
 	fmt.Println("\n✅ Example completed successfully!")
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mrlm-net/simbrief/pkg/types"
+)
+
+// DefaultOptionsCacheTTL is how long cached supported options are reused before refetching
+const DefaultOptionsCacheTTL = time.Hour
+
+// sharedOptionsCache is the process-wide cache used by clients created with WithSharedOptionsCache
+var sharedOptionsCache = newOptionsCache(DefaultOptionsCacheTTL)
+
+// WithSharedOptionsCache makes GetSupportedOptions use a process-wide cache keyed by the
+// inputs list URL, so clients for the same API (e.g. one per tenant API key) share a single
+// fetch. Entries expire after DefaultOptionsCacheTTL according to the client Clock.
+func WithSharedOptionsCache() Option {
+	return func(c *Client) {
+		c.optionsCache = sharedOptionsCache
+	}
+}
+
+// optionsCache stores supported options per URL, safe for concurrent use
+type optionsCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]optionsCacheEntry
+}
+
+type optionsCacheEntry struct {
+	options   *types.SupportedOptions
+	fetchedAt time.Time
+}
+
+func newOptionsCache(ttl time.Duration) *optionsCache {
+	return &optionsCache{ttl: ttl, entries: make(map[string]optionsCacheEntry)}
+}
+
+// get returns a copy of the cached options for key if they are younger than the TTL at now
+func (oc *optionsCache) get(key string, now time.Time) (*types.SupportedOptions, bool) {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+
+	entry, ok := oc.entries[key]
+	if !ok || now.Sub(entry.fetchedAt) >= oc.ttl {
+		return nil, false
+	}
+	return cloneSupportedOptions(entry.options), true
+}
+
+// set stores a copy of options for key
+func (oc *optionsCache) set(key string, options *types.SupportedOptions, now time.Time) {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+
+	oc.entries[key] = optionsCacheEntry{options: cloneSupportedOptions(options), fetchedAt: now}
+}
+
+// cloneSupportedOptions copies the option maps so callers cannot modify cached data
+func cloneSupportedOptions(options *types.SupportedOptions) *types.SupportedOptions {
+	clone := *options
+	clone.Aircraft = make(map[string]types.AircraftOption, len(options.Aircraft))
+	for id, aircraft := range options.Aircraft {
+		clone.Aircraft[id] = aircraft
+	}
+	clone.Layouts = make(map[string]types.LayoutOption, len(options.Layouts))
+	for id, layout := range options.Layouts {
+		clone.Layouts[id] = layout
+	}
+	return &clone
+}
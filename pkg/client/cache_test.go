@@ -0,0 +1,86 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type adjustableClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *adjustableClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *adjustableClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestSharedOptionsCache(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		fmt.Fprint(w, `{"aircraft": {"B738": {"id": "B738", "name": "737-800"}}, "layouts": {}}`)
+	}))
+	defer server.Close()
+
+	clock := &adjustableClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	tenantA := NewClientWithConfig(server.URL, nil, WithSharedOptionsCache())
+	tenantA.APIKey = "key-a"
+	tenantA.Clock = clock
+	tenantB := NewClientWithConfig(server.URL, nil, WithSharedOptionsCache())
+	tenantB.APIKey = "key-b"
+	tenantB.Clock = clock
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(c *Client) {
+			defer wg.Done()
+			options, err := c.GetSupportedOptions()
+			assert.NoError(t, err)
+			assert.Contains(t, options.Aircraft, "B738")
+		}([]*Client{tenantA, tenantB}[i%2])
+	}
+	wg.Wait()
+
+	// Concurrent first calls may race to fill the cache, later calls are served from it
+	before := atomic.LoadInt32(&hits)
+	require.GreaterOrEqual(t, before, int32(1))
+	_, err := tenantB.GetSupportedOptions()
+	require.NoError(t, err)
+	assert.Equal(t, before, atomic.LoadInt32(&hits))
+
+	// Cached data cannot be modified through a returned value
+	options, err := tenantA.GetSupportedOptions()
+	require.NoError(t, err)
+	delete(options.Aircraft, "B738")
+	options, err = tenantB.GetSupportedOptions()
+	require.NoError(t, err)
+	assert.Contains(t, options.Aircraft, "B738")
+
+	clock.Advance(DefaultOptionsCacheTTL)
+	_, err = tenantA.GetSupportedOptions()
+	require.NoError(t, err)
+	assert.Equal(t, before+1, atomic.LoadInt32(&hits))
+
+	// Clients without the option always fetch
+	uncached := NewClientWithConfig(server.URL, nil)
+	_, err = uncached.GetSupportedOptions()
+	require.NoError(t, err)
+	assert.Equal(t, before+2, atomic.LoadInt32(&hits))
+}
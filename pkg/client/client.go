@@ -1,12 +1,23 @@
 package client
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
+	"html"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/mrlm-net/simbrief/pkg/types"
@@ -24,26 +35,178 @@ const (
 
 	// Default timeout for HTTP requests
 	DefaultTimeout = 30 * time.Second
+
+	// DefaultPingTimeout is the timeout applied to health checks
+	DefaultPingTimeout = 5 * time.Second
+
+	// MaxGenerateURLLength is the longest dispatch URL considered safe for browsers and proxies
+	MaxGenerateURLLength = 8000
+
+	// DefaultGenerateTimeout bounds GenerateFlightPlan when the context has no deadline
+	DefaultGenerateTimeout = 2 * time.Minute
 )
 
+// Endpoints holds the API paths appended to the client BaseURL
+// Override them when running behind a proxy that rewrites paths
+type Endpoints struct {
+	XMLFetcher string // Fetch existing flight plan data
+	InputsList string // Get supported aircraft/layouts (JSON)
+	InputsXML  string // Get supported aircraft/layouts (XML)
+	Generate   string // Generate new flight plan
+}
+
+// DefaultEndpoints returns the official SimBrief API paths
+func DefaultEndpoints() Endpoints {
+	return Endpoints{
+		XMLFetcher: endpointXMLFetcher,
+		InputsList: endpointInputsList,
+		InputsXML:  endpointInputsXML,
+		Generate:   endpointGenerate,
+	}
+}
+
+// withDefaults fills empty paths with the official defaults
+func (e Endpoints) withDefaults() Endpoints {
+	defaults := DefaultEndpoints()
+	if e.XMLFetcher == "" {
+		e.XMLFetcher = defaults.XMLFetcher
+	}
+	if e.InputsList == "" {
+		e.InputsList = defaults.InputsList
+	}
+	if e.InputsXML == "" {
+		e.InputsXML = defaults.InputsXML
+	}
+	if e.Generate == "" {
+		e.Generate = defaults.Generate
+	}
+	return e
+}
+
 // Client represents a SimBrief API client
 type Client struct {
 	BaseURL    string
 	HTTPClient *http.Client
+
+	// Endpoints holds the API paths, empty paths fall back to DefaultEndpoints
+	Endpoints Endpoints
+
+	// APIKey is sent as api_key on fetch requests when set
+	APIKey string
+
+	// StrictValidation enables additional consistency checks in ValidateFlightPlanRequest
+	StrictValidation bool
+
+	// FuelPolicy, when set, is enforced by ValidateFlightPlanRequest under StrictValidation
+	FuelPolicy *types.FuelPolicy
+
+	// Clock provides the current time for time-dependent helpers, nil means the system clock
+	Clock Clock
+
+	// optionsCache caches GetSupportedOptions results, see WithSharedOptionsCache
+	optionsCache *optionsCache
+
+	// strictDecoding rejects unknown JSON fields, see WithStrictDecoding
+	strictDecoding bool
+}
+
+// Clock is a source of the current time, replaceable in tests
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock implements Clock using time.Now
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// now returns the current time from the configured Clock
+func (c *Client) now() time.Time {
+	if c.Clock == nil {
+		return systemClock{}.Now()
+	}
+	return c.Clock.Now()
+}
+
+// PlanAge returns how long ago the plan was generated according to the client Clock
+func (c *Client) PlanAge(plan *types.FlightPlanResponse) (time.Duration, error) {
+	generated, err := plan.GeneratedAt()
+	if err != nil {
+		return 0, err
+	}
+	return c.now().Sub(generated), nil
+}
+
+// IsStale reports whether the plan was generated more than maxAge ago
+func (c *Client) IsStale(plan *types.FlightPlanResponse, maxAge time.Duration) (bool, error) {
+	age, err := c.PlanAge(plan)
+	if err != nil {
+		return false, err
+	}
+	return age > maxAge, nil
+}
+
+// Option configures a Client at construction time
+type Option func(*Client)
+
+// WithInsecureSkipVerify disables TLS certificate verification.
+// Only use it for testing against a local mock with a self-signed certificate; never in
+// production. The setting is applied to the client's *http.Transport (a private clone when
+// the default transport is in use) and keeps working when SetUserAgent wraps the transport.
+// Clients with a custom RoundTripper that is not an *http.Transport are left unchanged.
+func WithInsecureSkipVerify() Option {
+	return func(c *Client) {
+		transport, err := c.httpTransport()
+		if err != nil {
+			return
+		}
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		} else {
+			transport.TLSClientConfig = transport.TLSClientConfig.Clone()
+		}
+		transport.TLSClientConfig.InsecureSkipVerify = true
+	}
+}
+
+// WithStrictDecoding makes JSON decoding in the flight plan fetchers and GetSupportedOptions
+// fail on fields the SDK does not model, to spot new SimBrief fields while debugging.
+// Fields below a type with its own decoder (e.g. nav log fixes) are not checked.
+// Off by default: SimBrief adds fields regularly, so never enable it in production.
+func WithStrictDecoding() Option {
+	return func(c *Client) {
+		c.strictDecoding = true
+	}
+}
+
+// strictFlightPlanResponse has the fields of FlightPlanResponse without its UnmarshalJSON,
+// which would otherwise hide unknown fields from a strict decoder
+type strictFlightPlanResponse types.FlightPlanResponse
+
+// decodeStrict decodes data into v, rejecting unknown fields
+func decodeStrict(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
 }
 
 // NewClient creates a new SimBrief API client
-func NewClient() *Client {
-	return &Client{
+func NewClient(opts ...Option) *Client {
+	c := &Client{
 		BaseURL: DefaultBaseURL,
 		HTTPClient: &http.Client{
 			Timeout: DefaultTimeout,
 		},
+		Endpoints: DefaultEndpoints(),
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
 }
 
 // NewClientWithConfig creates a new SimBrief API client with custom configuration
-func NewClientWithConfig(baseURL string, httpClient *http.Client) *Client {
+func NewClientWithConfig(baseURL string, httpClient *http.Client, opts ...Option) *Client {
 	if baseURL == "" {
 		baseURL = DefaultBaseURL
 	}
@@ -51,10 +214,15 @@ func NewClientWithConfig(baseURL string, httpClient *http.Client) *Client {
 		httpClient = &http.Client{Timeout: DefaultTimeout}
 	}
 
-	return &Client{
+	c := &Client{
 		BaseURL:    baseURL,
 		HTTPClient: httpClient,
+		Endpoints:  DefaultEndpoints(),
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
 }
 
 // GetFlightPlanByUserID retrieves the latest flight plan for a specific user ID
@@ -66,6 +234,97 @@ func (c *Client) GetFlightPlanByUserID(userID string) (*types.FlightPlanResponse
 	return c.fetchFlightPlan(req)
 }
 
+// SimBrief fetch statuses returned with a 400 status, e.g. {"fetch": {"status": "Error: Unknown UserID"}}
+const (
+	fetchStatusUnknownUser  = "Error: Unknown UserID"
+	fetchStatusNoFlightPlan = "Error: No flight plan on file for the specified user"
+)
+
+// VerifyUserID reports whether userID belongs to a SimBrief account by fetching its latest plan.
+// SimBrief answers a failed fetch with status 400 and a {"fetch": {"status": ...}} payload:
+//   - "Error: No flight plan on file for the specified user": the user exists, returns true
+//   - "Error: Unknown UserID": the user does not exist, returns false with a nil error
+//
+// Any other response is returned as an error.
+func (c *Client) VerifyUserID(ctx context.Context, userID string) (bool, error) {
+	req, err := types.NewFetchRequest(types.WithUserID(userID), types.WithJSON())
+	if err != nil {
+		return false, err
+	}
+
+	_, err = c.fetchFlightPlanContext(ctx, req)
+	if err == nil {
+		return true, nil
+	}
+
+	switch fetchStatus(err) {
+	case fetchStatusUnknownUser:
+		return false, nil
+	case fetchStatusNoFlightPlan:
+		return true, nil
+	default:
+		return false, err
+	}
+}
+
+// fetchStatus returns the SimBrief fetch status carried by a 400 fetch error, empty otherwise
+func fetchStatus(err error) string {
+	var apiErr types.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != http.StatusBadRequest {
+		return ""
+	}
+	return strings.TrimSpace(apiErr.Message)
+}
+
+// isNoFlightPlan reports whether err is SimBrief's "no flight plan on file" fetch status
+func isNoFlightPlan(err error) bool {
+	return fetchStatus(err) == fetchStatusNoFlightPlan
+}
+
+// GetFlightPlanMerged retrieves the latest flight plan for userID in both JSON and XML and
+// fills the fields left empty by the JSON response from the XML one. It is an opt-in
+// convenience for fields only one format carries and doubles the request count.
+func (c *Client) GetFlightPlanMerged(userID string) (*types.FlightPlanResponse, error) {
+	merged, err := c.fetchFlightPlan(&types.FetchRequest{UserID: userID, JSON: true})
+	if err != nil {
+		return nil, err
+	}
+	fromXML, err := c.fetchFlightPlan(&types.FetchRequest{UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+	fillZeroFields(reflect.ValueOf(merged).Elem(), reflect.ValueOf(fromXML).Elem())
+	return merged, nil
+}
+
+// fillZeroFields copies src into the zero-valued exported fields of dst, recursing into
+// structs that are partly set. Both values must have the same type.
+func fillZeroFields(dst, src reflect.Value) {
+	if !dst.CanSet() {
+		return
+	}
+	if dst.IsZero() {
+		dst.Set(src)
+		return
+	}
+	switch dst.Kind() {
+	case reflect.Struct:
+		for i := 0; i < dst.NumField(); i++ {
+			if dst.Type().Field(i).IsExported() {
+				fillZeroFields(dst.Field(i), src.Field(i))
+			}
+		}
+	case reflect.Ptr:
+		if !src.IsNil() && dst.Elem().Kind() == reflect.Struct {
+			fillZeroFields(dst.Elem(), src.Elem())
+		}
+	case reflect.Slice, reflect.Map:
+		if dst.Len() == 0 {
+			dst.Set(src)
+		}
+	}
+}
+
 // GetFlightPlanByUsername retrieves the latest flight plan for a specific username
 func (c *Client) GetFlightPlanByUsername(username string) (*types.FlightPlanResponse, error) {
 	req := &types.FetchRequest{
@@ -75,7 +334,8 @@ func (c *Client) GetFlightPlanByUsername(username string) (*types.FlightPlanResp
 	return c.fetchFlightPlan(req)
 }
 
-// GetFlightPlanByStaticID retrieves a specific flight plan using static ID
+// GetFlightPlanByStaticID retrieves a specific flight plan using static ID and user ID
+// Use GetFlightPlanByUsernameAndStaticID when only the username is known
 func (c *Client) GetFlightPlanByStaticID(userID, staticID string) (*types.FlightPlanResponse, error) {
 	req := &types.FetchRequest{
 		UserID:   userID,
@@ -85,12 +345,27 @@ func (c *Client) GetFlightPlanByStaticID(userID, staticID string) (*types.Flight
 	return c.fetchFlightPlan(req)
 }
 
+// GetFlightPlanByUsernameAndStaticID retrieves a specific flight plan using static ID and username
+func (c *Client) GetFlightPlanByUsernameAndStaticID(username, staticID string) (*types.FlightPlanResponse, error) {
+	req := &types.FetchRequest{
+		Username: username,
+		StaticID: staticID,
+		JSON:     true,
+	}
+	return c.fetchFlightPlan(req)
+}
+
 // GetFlightPlanXML retrieves flight plan data in XML format
 func (c *Client) GetFlightPlanXML(req *types.FetchRequest) ([]byte, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Force XML format
 	req.JSON = false
+	req = c.withAPIKey(req)
 
-	fullURL := c.BaseURL + endpointXMLFetcher + req.ToQueryParams()
+	fullURL := c.BaseURL + c.Endpoints.withDefaults().XMLFetcher + req.ToQueryParams()
 
 	httpReq, err := http.NewRequest("GET", fullURL, nil)
 	if err != nil {
@@ -108,21 +383,32 @@ func (c *Client) GetFlightPlanXML(req *types.FetchRequest) ([]byte, error) {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
+	if apiErr, ok := parseHTMLError(resp, body); ok {
+		return nil, newHTTPError(resp, body, fullURL, apiErr)
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		// Try to parse error from XML
 		var apiErr types.APIError
 		if err := xml.Unmarshal(body, &apiErr); err == nil {
-			return nil, apiErr
+			return nil, newHTTPError(resp, body, fullURL, apiErr)
 		}
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, newHTTPError(resp, body, fullURL, nil)
 	}
 
 	return body, nil
 }
 
 // GetSupportedOptions retrieves the list of supported aircraft types and plan formats
+// Results are served from the shared cache when the client uses WithSharedOptionsCache
 func (c *Client) GetSupportedOptions() (*types.SupportedOptions, error) {
-	fullURL := c.BaseURL + endpointInputsList
+	fullURL := c.BaseURL + c.Endpoints.withDefaults().InputsList
+
+	if c.optionsCache != nil {
+		if options, ok := c.optionsCache.get(fullURL, c.now()); ok {
+			return options, nil
+		}
+	}
 
 	req, err := http.NewRequest("GET", fullURL, nil)
 	if err != nil {
@@ -137,22 +423,291 @@ func (c *Client) GetSupportedOptions() (*types.SupportedOptions, error) {
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, newHTTPError(resp, body, fullURL, nil)
 	}
 
 	var options types.SupportedOptions
-	if err := json.NewDecoder(resp.Body).Decode(&options); err != nil {
+	decoder := json.NewDecoder(resp.Body)
+	if c.strictDecoding {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(&options); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
+	if c.optionsCache != nil {
+		c.optionsCache.set(fullURL, &options, c.now())
+	}
+
 	return &options, nil
 }
 
+// Ping checks that the SimBrief API is reachable using a lightweight request to the inputs list endpoint
+// Returns nil on success and an error wrapping types.ErrAPIUnavailable otherwise
+func (c *Client) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, DefaultPingTimeout)
+	defer cancel()
+
+	fullURL := c.BaseURL + c.Endpoints.withDefaults().InputsList
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.HTTPClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("%w: %v", types.ErrAPIUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%w: %w", types.ErrAPIUnavailable, newHTTPError(resp, body, fullURL, nil))
+	}
+
+	return nil
+}
+
 // GenerateFlightPlanURL generates a URL for flight plan generation
 // Note: Actual flight plan generation requires browser popup authentication
 func (c *Client) GenerateFlightPlanURL(req *types.FlightPlanRequest) string {
 	values := req.ToURLValues()
-	return c.BaseURL + endpointGenerate + "?" + values.Encode()
+	return c.BaseURL + c.Endpoints.withDefaults().Generate + "?" + values.Encode()
+}
+
+// GenerateFlightPlanURLWithKey is GenerateFlightPlanURL with api_key set to apiKey for this
+// call only, overriding the client APIKey. The returned URL contains the key: pass it
+// through RedactURL before logging it.
+func (c *Client) GenerateFlightPlanURLWithKey(req *types.FlightPlanRequest, apiKey string) string {
+	values := req.ToURLValues()
+	if apiKey != "" {
+		values.Set("api_key", apiKey)
+	}
+	return c.BaseURL + c.Endpoints.withDefaults().Generate + "?" + values.Encode()
+}
+
+// GenerateFlightPlanURLs returns the generate URL of each request, in order. A request that
+// fails validation gets an empty URL and its error is included in the joined error, so the
+// result always has one entry per request.
+func (c *Client) GenerateFlightPlanURLs(reqs []*types.FlightPlanRequest) ([]string, error) {
+	urls := make([]string, len(reqs))
+	var errs []error
+	for i, req := range reqs {
+		if req == nil {
+			errs = append(errs, fmt.Errorf("request %d: request is nil", i))
+			continue
+		}
+		if err := req.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("request %d: %w", i, err))
+			continue
+		}
+		urls[i] = c.GenerateFlightPlanURL(req)
+	}
+	return urls, errors.Join(errs...)
+}
+
+// GenerateFlightPlanURLsByStaticID returns the generate URLs keyed by each request's StaticID.
+// Requests without a StaticID, with a duplicate StaticID or failing validation are skipped
+// and reported in the joined error.
+func (c *Client) GenerateFlightPlanURLsByStaticID(reqs []*types.FlightPlanRequest) (map[string]string, error) {
+	urls := make(map[string]string, len(reqs))
+	var errs []error
+	for i, req := range reqs {
+		switch {
+		case req == nil:
+			errs = append(errs, fmt.Errorf("request %d: request is nil", i))
+		case req.StaticID == "":
+			errs = append(errs, fmt.Errorf("request %d: static ID is required", i))
+		case urls[req.StaticID] != "":
+			errs = append(errs, fmt.Errorf("request %d: duplicate static ID %q", i, req.StaticID))
+		default:
+			if err := req.Validate(); err != nil {
+				errs = append(errs, fmt.Errorf("request %d (%s): %w", i, req.StaticID, err))
+				continue
+			}
+			urls[req.StaticID] = c.GenerateFlightPlanURL(req)
+		}
+	}
+	return urls, errors.Join(errs...)
+}
+
+// GenerateFlightPlanURLsForFormats returns a generate URL per plan format, each built from
+// a clone of req with PlanFormat set; req itself is not modified. When the client uses
+// WithSharedOptionsCache, formats missing from the supported layouts are left out.
+func (c *Client) GenerateFlightPlanURLsForFormats(req *types.FlightPlanRequest, formats []types.PlanFormat) map[types.PlanFormat]string {
+	var layouts map[string]types.LayoutOption
+	if c.optionsCache != nil {
+		if options, err := c.GetSupportedOptions(); err == nil {
+			layouts = options.Layouts
+		}
+	}
+
+	urls := make(map[types.PlanFormat]string, len(formats))
+	for _, format := range formats {
+		if layouts != nil && format != types.PlanFormatDefault && !hasLayout(layouts, string(format)) {
+			continue
+		}
+		clone := req.Clone()
+		clone.PlanFormat = string(format)
+		urls[format] = c.GenerateFlightPlanURL(clone)
+	}
+	return urls
+}
+
+// hasLayout reports whether id names one of the layouts, ignoring case
+func hasLayout(layouts map[string]types.LayoutOption, id string) bool {
+	for key, layout := range layouts {
+		if strings.EqualFold(key, id) || strings.EqualFold(layout.ID, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactedAPIKey replaces the api_key value in URLs meant for logs or sharing
+const redactedAPIKey = "REDACTED"
+
+// CurlOption configures CurlForGenerate
+type CurlOption func(*curlConfig)
+
+type curlConfig struct {
+	revealAPIKey bool
+}
+
+// RevealAPIKey includes the client API key in the generated command instead of redacting it
+func RevealAPIKey() CurlOption {
+	return func(cfg *curlConfig) { cfg.revealAPIKey = true }
+}
+
+// CurlForGenerate returns a runnable curl command requesting the dispatch URL for req,
+// useful for reproducing issues. The client API key is included as api_key and redacted
+// unless RevealAPIKey is passed.
+func (c *Client) CurlForGenerate(req *types.FlightPlanRequest, opts ...CurlOption) string {
+	var cfg curlConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	values := req.ToURLValues()
+	if c.APIKey != "" {
+		values.Set("api_key", c.APIKey)
+	}
+	fullURL := c.BaseURL + c.Endpoints.withDefaults().Generate + "?" + values.Encode()
+	if !cfg.revealAPIKey {
+		fullURL = RedactURL(fullURL)
+	}
+
+	return "curl -sSL " + shellQuote(fullURL)
+}
+
+// RedactURL masks the api_key query parameter, leaving the rest of the URL untouched
+// Use it before logging URLs that may carry an API key
+func RedactURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	query := parsed.Query()
+	if query.Get("api_key") == "" {
+		return rawURL
+	}
+	query.Set("api_key", redactedAPIKey)
+	parsed.RawQuery = query.Encode()
+	return parsed.String()
+}
+
+// shellQuote wraps s in single quotes for POSIX shells
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Polling intervals used by GenerateFlightPlan, doubled after each attempt up to the maximum
+var (
+	generatePollInterval    = time.Second
+	generatePollMaxInterval = 8 * time.Second
+)
+
+// GenerateFlightPlan generates a flight plan server-side using the client API key and
+// waits for it to become available, fetching it by userID and static ID. A static ID is
+// assigned when req has none. Polling continues while SimBrief reports no flight plan on
+// file; any other fetch error is returned immediately. If the plan does not appear before
+// the context deadline (or DefaultGenerateTimeout) a *types.GenerateTimeoutError carrying
+// the last fetch error is returned.
+func (c *Client) GenerateFlightPlan(ctx context.Context, req *types.FlightPlanRequest, userID string) (*types.FlightPlanResponse, error) {
+	if c.APIKey == "" {
+		return nil, fmt.Errorf("%w: server-side generation requires an API key", types.ErrInvalidAPIKey)
+	}
+	if userID == "" {
+		return nil, types.ErrMissingUserID
+	}
+	if err := c.ValidateFlightPlanRequest(req); err != nil {
+		return nil, err
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultGenerateTimeout)
+		defer cancel()
+	}
+
+	req = req.Clone()
+	if req.StaticID == "" {
+		req.StaticID = fmt.Sprintf("SDK_%d", c.now().UnixNano())
+	}
+
+	fetchReq, err := types.NewFetchRequest(
+		types.WithUserID(userID),
+		types.WithStaticID(req.StaticID),
+		types.WithJSON(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Remember any plan already stored under the static ID so it is not mistaken for the new one
+	var previousRequestID string
+	if previous, err := c.fetchFlightPlanContext(ctx, fetchReq); err == nil {
+		previousRequestID = previous.Params.RequestID
+	}
+
+	values := req.ToURLValues()
+	values.Set("api_key", c.APIKey)
+	values.Set("userid", userID)
+	generateURL := c.BaseURL + c.Endpoints.withDefaults().Generate + "?" + values.Encode()
+	resp, body, err := c.get(ctx, generateURL)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, newHTTPError(resp, body, generateURL, nil)
+	}
+
+	start := c.now()
+	interval := generatePollInterval
+	var lastErr error
+	for {
+		plan, err := c.fetchFlightPlanContext(ctx, fetchReq)
+		switch {
+		case err == nil && plan.Params.RequestID != previousRequestID:
+			return plan, nil
+		case err != nil && ctx.Err() == nil && !isNoFlightPlan(err):
+			// Only "no flight plan yet" means generation is still running
+			return nil, err
+		case err != nil && isNoFlightPlan(err):
+			lastErr = err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, &types.GenerateTimeoutError{StaticID: req.StaticID, Waited: c.now().Sub(start), Err: ctx.Err(), LastErr: lastErr}
+		case <-time.After(interval):
+		}
+
+		interval *= 2
+		if interval > generatePollMaxInterval {
+			interval = generatePollMaxInterval
+		}
+	}
 }
 
 // ValidateFlightPlanRequest validates that a flight plan request has all required fields
@@ -176,34 +731,193 @@ func (c *Client) ValidateFlightPlanRequest(req *types.FlightPlanRequest) error {
 	}
 
 	// Validate departure time if provided
-	if req.DepartureHour != 0 && (req.DepartureHour < 0 || req.DepartureHour > 23) {
+	if req.DepartureHour != nil && (*req.DepartureHour < 0 || *req.DepartureHour > 23) {
 		return fmt.Errorf("departure hour must be between 0 and 23")
 	}
-	if req.DepartureMinute != 0 && (req.DepartureMinute < 0 || req.DepartureMinute > 59) {
+	if req.DepartureMinute != nil && (*req.DepartureMinute < 0 || *req.DepartureMinute > 59) {
 		return fmt.Errorf("departure minute must be between 0 and 59")
 	}
 
+	// Validate scheduled time if provided
+	if req.ScheduledHour != nil && *req.ScheduledHour < 0 {
+		return fmt.Errorf("scheduled hour cannot be negative")
+	}
+	if req.ScheduledMinute != nil && (*req.ScheduledMinute < 0 || *req.ScheduledMinute > 59) {
+		return fmt.Errorf("scheduled minute must be between 0 and 59")
+	}
+
+	// Validate taxi times if provided
+	if req.TaxiOut != nil && *req.TaxiOut < 0 {
+		return fmt.Errorf("taxi out time cannot be negative")
+	}
+	if req.TaxiIn != nil && *req.TaxiIn < 0 {
+		return fmt.Errorf("taxi in time cannot be negative")
+	}
+
+	if c.StrictValidation {
+		return validateStrict(req, c.FuelPolicy)
+	}
+
+	return nil
+}
+
+// PreflightCheck reports every problem that would keep req from generating cleanly:
+// each missing required field, malformed fields (ValidateFlightPlanRequest), an invalid
+// flight number, an overlong route or dispatch URL, Extra parameters clashing with modeled
+// ones and Extra parameters SimBrief does not accept (types.IsSimBriefParam).
+// An empty result means the request is ready to submit.
+func (c *Client) PreflightCheck(req *types.FlightPlanRequest) []string {
+	var issues []string
+
+	missing := false
+	for _, field := range []struct {
+		value string
+		err   error
+	}{
+		{req.Origin, types.ErrMissingOrigin},
+		{req.Destination, types.ErrMissingDestination},
+		{req.Aircraft, types.ErrMissingAircraft},
+	} {
+		if field.value == "" {
+			issues = append(issues, field.err.Error())
+			missing = true
+		}
+	}
+	// ValidateFlightPlanRequest stops at the first problem, so only run it for the format checks
+	if !missing {
+		if err := c.ValidateFlightPlanRequest(req); err != nil {
+			issues = append(issues, err.Error())
+		}
+	}
+
+	if req.FlightNumber != "" {
+		if err := types.ValidateFlightNumber(req.FlightNumber); err != nil {
+			issues = append(issues, err.Error())
+		}
+	}
+
+	if req.Date != "" {
+		if err := types.ValidateDate(req.Date); err != nil {
+			issues = append(issues, err.Error())
+		}
+	}
+
+	if err := req.ValidateExtra(); err != nil {
+		issues = append(issues, err.Error())
+	}
+
+	if len(req.Route) > types.MaxRouteLength {
+		issues = append(issues, fmt.Sprintf("route is %d characters, limit is %d", len(req.Route), types.MaxRouteLength))
+	}
+
+	// Conflicts with modeled keys are reported by ValidateExtra above
+	keys := make([]string, 0, len(req.Extra))
+	for key := range req.Extra {
+		if !types.IsSimBriefParam(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		issues = append(issues, fmt.Sprintf("unrecognized parameter %q", key))
+	}
+
+	if length := len(c.GenerateFlightPlanURL(req)); length > MaxGenerateURLLength {
+		issues = append(issues, fmt.Sprintf("dispatch URL is %d characters, limit is %d", length, MaxGenerateURLLength))
+	}
+
+	return issues
+}
+
+// CheckFeatureSupport warns about requested features the chosen aircraft has no data for,
+// which SimBrief silently ignores: runway analysis (TLR), a cost index and flight maps.
+// The aircraft list comes from GetSupportedOptions, so enable WithSharedOptionsCache
+// to avoid a request per call. An empty result means no unsupported feature was found.
+func (c *Client) CheckFeatureSupport(req *types.FlightPlanRequest) []string {
+	options, err := c.GetSupportedOptions()
+	if err != nil {
+		return []string{fmt.Sprintf("cannot check feature support: %v", err)}
+	}
+
+	aircraft, ok := options.Aircraft[strings.ToUpper(strings.TrimSpace(req.Aircraft))]
+	if !ok {
+		return []string{fmt.Sprintf("aircraft %q is not in the supported aircraft list", req.Aircraft)}
+	}
+
+	var warnings []string
+	if req.RunwayAnalysis != nil && *req.RunwayAnalysis && !aircraft.TLRData {
+		warnings = append(warnings, fmt.Sprintf("aircraft %s has no TLR data, runway analysis will be ignored", aircraft.ID))
+	}
+	if req.CostIndex != "" && !aircraft.CostIndexData {
+		warnings = append(warnings, fmt.Sprintf("aircraft %s has no cost index data, cost index %s will be ignored", aircraft.ID, req.CostIndex))
+	}
+	if req.Maps != "" && !strings.EqualFold(req.Maps, "none") && !aircraft.ChartData {
+		warnings = append(warnings, fmt.Sprintf("aircraft %s has no chart data, %s maps will be ignored", aircraft.ID, req.Maps))
+	}
+	return warnings
+}
+
+// withAPIKey returns a copy of the fetch request carrying the client API key when the request has none
+func (c *Client) withAPIKey(req *types.FetchRequest) *types.FetchRequest {
+	if req.APIKey != "" || c.APIKey == "" {
+		return req
+	}
+	withKey := *req
+	withKey.APIKey = c.APIKey
+	return &withKey
+}
+
+// validateStrict runs the consistency checks enabled by StrictValidation
+func validateStrict(req *types.FlightPlanRequest, policy *types.FuelPolicy) error {
+	alternates := []struct {
+		key   string
+		value string
+	}{
+		{"altn", req.Alternate},
+		{"altn_1_id", req.Altn1ID},
+		{"altn_2_id", req.Altn2ID},
+		{"altn_3_id", req.Altn3ID},
+		{"altn_4_id", req.Altn4ID},
+	}
+	for _, altn := range alternates {
+		if altn.value == "" {
+			continue
+		}
+		if strings.EqualFold(altn.value, req.Origin) {
+			return fmt.Errorf("alternate %s (%s) must differ from origin", altn.key, altn.value)
+		}
+		if strings.EqualFold(altn.value, req.Destination) {
+			return fmt.Errorf("alternate %s (%s) must differ from destination", altn.key, altn.value)
+		}
+	}
+
+	if policy != nil {
+		return types.ValidateFuelPolicy(req, *policy)
+	}
+
 	return nil
 }
 
 // fetchFlightPlan is a helper method to fetch flight plan data
 func (c *Client) fetchFlightPlan(req *types.FetchRequest) (*types.FlightPlanResponse, error) {
-	fullURL := c.BaseURL + endpointXMLFetcher + req.ToQueryParams()
+	return c.fetchFlightPlanContext(context.Background(), req)
+}
 
-	httpReq, err := http.NewRequest("GET", fullURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+// fetchFlightPlanContext is fetchFlightPlan bound to a context
+func (c *Client) fetchFlightPlanContext(ctx context.Context, req *types.FetchRequest) (*types.FlightPlanResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
 	}
+	req = c.withAPIKey(req)
+	fullURL := c.BaseURL + c.Endpoints.withDefaults().XMLFetcher + req.ToQueryParams()
 
-	resp, err := c.HTTPClient.Do(httpReq)
+	resp, body, err := c.get(ctx, fullURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+	if apiErr, ok := parseHTMLError(resp, body); ok {
+		return nil, newHTTPError(resp, body, fullURL, apiErr)
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -211,20 +925,28 @@ func (c *Client) fetchFlightPlan(req *types.FetchRequest) (*types.FlightPlanResp
 		if req.JSON {
 			var apiErr types.APIError
 			if err := json.Unmarshal(body, &apiErr); err == nil {
-				return nil, apiErr
+				if apiErr.Code == 0 {
+					apiErr.Code = resp.StatusCode
+				}
+				return nil, newHTTPError(resp, body, fullURL, apiErr)
 			}
 		} else {
 			var apiErr types.APIError
 			if err := xml.Unmarshal(body, &apiErr); err == nil {
-				return nil, apiErr
+				return nil, newHTTPError(resp, body, fullURL, apiErr)
 			}
 		}
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, newHTTPError(resp, body, fullURL, nil)
 	}
 
 	var flightPlan types.FlightPlanResponse
 
 	if req.JSON {
+		if c.strictDecoding {
+			if err := decodeStrict(body, &strictFlightPlanResponse{}); err != nil {
+				return nil, fmt.Errorf("failed to decode JSON response: %w", err)
+			}
+		}
 		if err := json.Unmarshal(body, &flightPlan); err != nil {
 			return nil, fmt.Errorf("failed to decode JSON response: %w", err)
 		}
@@ -237,9 +959,73 @@ func (c *Client) fetchFlightPlan(req *types.FetchRequest) (*types.FlightPlanResp
 	return &flightPlan, nil
 }
 
+// newHTTPError wraps a failed response, redacting the API key from the request URL
+func newHTTPError(resp *http.Response, body []byte, fullURL string, apiErr error) *types.HTTPError {
+	return &types.HTTPError{
+		StatusCode: resp.StatusCode,
+		Body:       string(body),
+		URL:        RedactURL(fullURL),
+		Err:        apiErr,
+	}
+}
+
+// parseHTMLError detects an HTML page returned instead of XML/JSON data
+// SimBrief serves HTML error pages (sometimes with a 200 status) when the fetcher fails
+func parseHTMLError(resp *http.Response, body []byte) (types.APIError, bool) {
+	trimmed := strings.ToLower(strings.TrimSpace(string(body)))
+	isHTML := strings.HasPrefix(trimmed, "<!doctype") || strings.HasPrefix(trimmed, "<html")
+	if !isHTML && strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		// PHP serves text/html by default, so trust the header only when the body is not data
+		isHTML = !isDataPayload(trimmed)
+	}
+	if !isHTML {
+		return types.APIError{}, false
+	}
+
+	message := extractHTMLText(string(body))
+	if message == "" {
+		message = fmt.Sprintf("unexpected HTML response with status %d", resp.StatusCode)
+	}
+
+	return types.APIError{Message: message, Code: resp.StatusCode}, true
+}
+
+// isDataPayload reports whether a lowercased, trimmed body looks like JSON or XML data
+func isDataPayload(trimmed string) bool {
+	for _, prefix := range []string{"{", "[", "<?xml", "<ofp"} {
+		if strings.HasPrefix(trimmed, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	htmlTitlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	htmlH1Pattern    = regexp.MustCompile(`(?is)<h1[^>]*>(.*?)</h1>`)
+	htmlBodyPattern  = regexp.MustCompile(`(?is)<body[^>]*>(.*?)</body>`)
+	htmlTagPattern   = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// extractHTMLText returns the most descriptive text of an HTML page (title, heading or body text)
+func extractHTMLText(page string) string {
+	for _, pattern := range []*regexp.Regexp{htmlTitlePattern, htmlH1Pattern, htmlBodyPattern} {
+		if match := pattern.FindStringSubmatch(page); match != nil {
+			text := strings.Join(strings.Fields(htmlTagPattern.ReplaceAllString(match[1], " ")), " ")
+			if text != "" {
+				if len(text) > 200 {
+					text = text[:200] + "..."
+				}
+				return html.UnescapeString(text)
+			}
+		}
+	}
+	return ""
+}
+
 // GetDirectEditURL generates a URL to edit a specific flight plan on SimBrief website
 func (c *Client) GetDirectEditURL(staticID string) string {
-	return fmt.Sprintf("%s/system/dispatch.php?editflight=last&static_id=%s", c.BaseURL, url.QueryEscape(staticID))
+	return fmt.Sprintf("%s%s?editflight=last&static_id=%s", c.BaseURL, c.Endpoints.withDefaults().Generate, url.QueryEscape(staticID))
 }
 
 // GetAircraftTypes retrieves just the aircraft types from supported options
@@ -260,11 +1046,233 @@ func (c *Client) GetPlanFormats() (map[string]types.LayoutOption, error) {
 	return options.Layouts, nil
 }
 
+// GetAircraftTypesSorted retrieves the aircraft types sorted by ID, for stable rendering.
+// Use SupportedOptions.TopAircraft to order them by popularity instead.
+func (c *Client) GetAircraftTypesSorted() ([]types.AircraftOption, error) {
+	options, err := c.GetSupportedOptions()
+	if err != nil {
+		return nil, err
+	}
+	aircraft := make([]types.AircraftOption, 0, len(options.Aircraft))
+	for _, option := range options.Aircraft {
+		aircraft = append(aircraft, option)
+	}
+	sort.Slice(aircraft, func(i, j int) bool { return aircraft[i].ID < aircraft[j].ID })
+	return aircraft, nil
+}
+
+// GetPlanFormatsSorted retrieves the plan formats sorted by ID, for stable rendering.
+// Use SupportedOptions.TopLayouts to order them by popularity instead.
+func (c *Client) GetPlanFormatsSorted() ([]types.LayoutOption, error) {
+	options, err := c.GetSupportedOptions()
+	if err != nil {
+		return nil, err
+	}
+	layouts := make([]types.LayoutOption, 0, len(options.Layouts))
+	for _, option := range options.Layouts {
+		layouts = append(layouts, option)
+	}
+	sort.Slice(layouts, func(i, j int) bool { return layouts[i].ID < layouts[j].ID })
+	return layouts, nil
+}
+
+// DownloadMaps downloads all map images referenced by the flight plan
+// Returns an empty slice when the plan has no maps (e.g. maps were not requested)
+func (c *Client) DownloadMaps(ctx context.Context, plan *types.FlightPlanResponse) ([]types.DownloadedMap, error) {
+	if plan == nil {
+		return nil, fmt.Errorf("flight plan is required")
+	}
+
+	maps := make([]types.DownloadedMap, 0, len(plan.Images.Maps))
+	for _, m := range plan.Images.Maps {
+		if m.Link == "" {
+			continue
+		}
+
+		mapURL := m.URL(plan.Images.Directory)
+		resp, body, err := c.get(ctx, mapURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download map %q: %w", m.Name, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to download map %q: %w", m.Name, newHTTPError(resp, body, mapURL, nil))
+		}
+
+		maps = append(maps, types.DownloadedMap{
+			Name:        m.Name,
+			URL:         mapURL,
+			ContentType: resp.Header.Get("Content-Type"),
+			Data:        body,
+		})
+	}
+
+	return maps, nil
+}
+
+// FetchRouteCoordinates downloads the SimBrief-generated KML file of the plan and returns
+// the coordinates of its route LineString. Returns types.ErrNoKMLFile when the plan has no KML link.
+func (c *Client) FetchRouteCoordinates(ctx context.Context, fp *types.FlightPlanResponse) ([]types.LatLon, error) {
+	if fp == nil {
+		return nil, fmt.Errorf("flight plan is required")
+	}
+	kmlURL, ok := fp.Files.KMLURL()
+	if !ok {
+		return nil, types.ErrNoKMLFile
+	}
+
+	resp, body, err := c.get(ctx, kmlURL)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download KML: %w", newHTTPError(resp, body, kmlURL, nil))
+	}
+
+	return parseKMLLineString(body)
+}
+
+// parseKMLLineString extracts the coordinates of the first LineString in a KML document
+func parseKMLLineString(data []byte) ([]types.LatLon, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	inLineString := false
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("KML contains no LineString coordinates")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse KML: %w", err)
+		}
+
+		switch el := token.(type) {
+		case xml.StartElement:
+			if el.Name.Local == "LineString" {
+				inLineString = true
+			} else if el.Name.Local == "coordinates" && inLineString {
+				var text string
+				if err := decoder.DecodeElement(&text, &el); err != nil {
+					return nil, fmt.Errorf("failed to parse KML coordinates: %w", err)
+				}
+				return parseKMLCoordinates(text)
+			}
+		case xml.EndElement:
+			if el.Name.Local == "LineString" {
+				inLineString = false
+			}
+		}
+	}
+}
+
+// parseKMLCoordinates parses whitespace separated "lon,lat[,alt]" tuples
+func parseKMLCoordinates(text string) ([]types.LatLon, error) {
+	tuples := strings.Fields(text)
+	coords := make([]types.LatLon, 0, len(tuples))
+
+	for _, tuple := range tuples {
+		parts := strings.Split(tuple, ",")
+		if len(parts) < 2 {
+			return nil, fmt.Errorf("invalid KML coordinate: %s", tuple)
+		}
+		lon, err := strconv.ParseFloat(parts[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid KML longitude: %s", parts[0])
+		}
+		lat, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid KML latitude: %s", parts[1])
+		}
+		coords = append(coords, types.LatLon{Lat: lat, Lon: lon})
+	}
+
+	return coords, nil
+}
+
+// get performs a GET request with context and returns the response together with its body
+func (c *Client) get(ctx context.Context, fullURL string) (*http.Response, []byte, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	return resp, body, nil
+}
+
 // SetTimeout sets the HTTP client timeout
 func (c *Client) SetTimeout(timeout time.Duration) {
 	c.HTTPClient.Timeout = timeout
 }
 
+// SetDialTimeout limits how long establishing the TCP connection may take.
+// Transport timeouts only bound their phase of the request: the overall Timeout (SetTimeout)
+// still applies to the whole request, so a long Timeout can be combined with short dial,
+// TLS and response header timeouts to fail fast on unreachable hosts.
+// Returns an error when the client uses a custom RoundTripper that is not an *http.Transport.
+func (c *Client) SetDialTimeout(timeout time.Duration) error {
+	transport, err := c.httpTransport()
+	if err != nil {
+		return err
+	}
+	transport.DialContext = (&net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}).DialContext
+	return nil
+}
+
+// SetTLSHandshakeTimeout limits how long the TLS handshake may take, see SetDialTimeout
+func (c *Client) SetTLSHandshakeTimeout(timeout time.Duration) error {
+	transport, err := c.httpTransport()
+	if err != nil {
+		return err
+	}
+	transport.TLSHandshakeTimeout = timeout
+	return nil
+}
+
+// SetResponseHeaderTimeout limits how long to wait for response headers after the request
+// has been written, see SetDialTimeout
+func (c *Client) SetResponseHeaderTimeout(timeout time.Duration) error {
+	transport, err := c.httpTransport()
+	if err != nil {
+		return err
+	}
+	transport.ResponseHeaderTimeout = timeout
+	return nil
+}
+
+// httpTransport returns the *http.Transport used by the client, looking through the
+// User-Agent wrapper. The shared http.DefaultTransport is replaced by a private clone
+// so that changing its settings does not affect other clients.
+func (c *Client) httpTransport() (*http.Transport, error) {
+	slot := &c.HTTPClient.Transport
+	if ua, ok := (*slot).(*userAgentTransport); ok {
+		slot = &ua.Transport
+	}
+
+	switch t := (*slot).(type) {
+	case nil:
+	case *http.Transport:
+		if t != http.DefaultTransport {
+			return t, nil
+		}
+	default:
+		return nil, fmt.Errorf("custom transport %T does not support timeout settings", t)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	*slot = transport
+	return transport, nil
+}
+
 // SetUserAgent sets a custom User-Agent header for requests
 func (c *Client) SetUserAgent(userAgent string) {
 	// Create a custom transport that adds the User-Agent header
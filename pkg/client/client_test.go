@@ -1,19 +1,37 @@
 package client
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/mrlm-net/simbrief/pkg/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+func intPtr(v int) *int {
+	return &v
+}
+
 func TestNewClient(t *testing.T) {
 	client := NewClient()
 
 	assert.Equal(t, DefaultBaseURL, client.BaseURL)
 	assert.NotNil(t, client.HTTPClient)
 	assert.Equal(t, DefaultTimeout, client.HTTPClient.Timeout)
+	assert.Equal(t, DefaultEndpoints(), client.Endpoints)
 }
 
 func TestNewClientWithConfig(t *testing.T) {
@@ -86,7 +104,7 @@ func TestValidateFlightPlanRequest(t *testing.T) {
 				Origin:        "KJFK",
 				Destination:   "KLAX",
 				Aircraft:      "B738",
-				DepartureHour: 25,
+				DepartureHour: intPtr(25),
 			},
 			wantErr: true,
 			errMsg:  "departure hour must be between 0 and 23",
@@ -97,11 +115,33 @@ func TestValidateFlightPlanRequest(t *testing.T) {
 				Origin:          "KJFK",
 				Destination:     "KLAX",
 				Aircraft:        "B738",
-				DepartureMinute: 60,
+				DepartureMinute: intPtr(60),
 			},
 			wantErr: true,
 			errMsg:  "departure minute must be between 0 and 59",
 		},
+		{
+			name: "midnight departure",
+			request: &types.FlightPlanRequest{
+				Origin:          "KJFK",
+				Destination:     "KLAX",
+				Aircraft:        "B738",
+				DepartureHour:   intPtr(0),
+				DepartureMinute: intPtr(0),
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid scheduled minute",
+			request: &types.FlightPlanRequest{
+				Origin:          "KJFK",
+				Destination:     "KLAX",
+				Aircraft:        "B738",
+				ScheduledMinute: intPtr(75),
+			},
+			wantErr: true,
+			errMsg:  "scheduled minute must be between 0 and 59",
+		},
 	}
 
 	for _, tt := range tests {
@@ -166,6 +206,8 @@ func TestFlightPlanBuilder(t *testing.T) {
 		Passengers(150).
 		Units(types.UnitsLBS).
 		Registration("N12345").
+		Fin("3512").
+		PilotID("12345").
 		StaticID("TEST_FLIGHT").
 		Build()
 
@@ -176,12 +218,16 @@ func TestFlightPlanBuilder(t *testing.T) {
 	assert.Equal(t, "UAL", request.Airline)
 	assert.Equal(t, "1234", request.FlightNumber)
 	assert.Equal(t, "KLAS", request.Alternate)
-	assert.Equal(t, 14, request.DepartureHour)
-	assert.Equal(t, 30, request.DepartureMinute)
+	require.NotNil(t, request.DepartureHour)
+	require.NotNil(t, request.DepartureMinute)
+	assert.Equal(t, 14, *request.DepartureHour)
+	assert.Equal(t, 30, *request.DepartureMinute)
 	assert.Equal(t, "FL340", request.Altitude)
 	assert.Equal(t, 150, request.Passengers)
 	assert.Equal(t, types.UnitsLBS, request.Units)
 	assert.Equal(t, "N12345", request.Registration)
+	assert.Equal(t, "3512", request.FinNumber)
+	assert.Equal(t, "12345", request.PilotID)
 	assert.Equal(t, "TEST_FLIGHT", request.StaticID)
 }
 
@@ -224,6 +270,79 @@ func TestFlightPlanBuilderCustomAircraft(t *testing.T) {
 	assert.Equal(t, 99.3, request.AircraftData.OEW)
 }
 
+func TestAircraftDataBuilder(t *testing.T) {
+	data, err := NewAircraftData().
+		Identity("B38M", "737 MAX 8", "LEAP-1B28").
+		ICAOFlightPlan("M", "SDE3FGHIRWY", "LB1").
+		PBN("PBN/A1B1C1D1S2").
+		Weights(99.3, 145.4, 181.2, 152.8, 46.0).
+		Build()
+
+	require.NoError(t, err)
+	assert.Equal(t, "B38M", data.ICAO)
+	assert.Equal(t, "M", data.Category)
+	assert.Equal(t, "PBN/A1B1C1D1S2", data.PBN)
+	assert.Equal(t, 181.2, data.MTOW)
+}
+
+func TestAircraftDataBuilderValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *AircraftDataBuilder
+		errMsg  string
+	}{
+		{
+			name:    "partial ICAO fields",
+			builder: NewAircraftData().ICAOFlightPlan("M", "SDE3FGHIRWY", ""),
+			errMsg:  "must be specified together",
+		},
+		{
+			name:    "PBN without prefix",
+			builder: NewAircraftData().PBN("A1B1C1D1"),
+			errMsg:  "PBN must start with",
+		},
+		{
+			name:    "invalid equipment designator",
+			builder: NewAircraftData().ICAOFlightPlan("M", "SDE4FGHIRWY", "LB1"),
+			errMsg:  "E must be followed by one of 123",
+		},
+		{
+			name:    "two SSR modes",
+			builder: NewAircraftData().ICAOFlightPlan("M", "SDE3FGHIRWY", "CS"),
+			errMsg:  "at most one SSR mode",
+		},
+		{
+			name:    "MLW above MTOW",
+			builder: NewAircraftData().Weights(99.3, 145.4, 150.0, 152.8, 46.0),
+			errMsg:  "MLW",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.builder.Build()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestValidateEquipmentAndTransponder(t *testing.T) {
+	for _, equip := range []string{"SDE3FGHIRWY", "N", "SDE1E2E3GIJ1J5M1P2RWXYZ"} {
+		assert.NoError(t, types.ValidateEquipment(equip), equip)
+	}
+	for _, equip := range []string{"", "SQ", "SDFS", "NS", "sde3", "SJ"} {
+		assert.Error(t, types.ValidateEquipment(equip), equip)
+	}
+
+	for _, transponder := range []string{"LB1", "SB1D1", "N", "EB2U1"} {
+		assert.NoError(t, types.ValidateTransponder(transponder), transponder)
+	}
+	for _, transponder := range []string{"", "Q", "B3", "CS", "NC", "LB1B1"} {
+		assert.Error(t, types.ValidateTransponder(transponder), transponder)
+	}
+}
+
 func TestAircraftDataString(t *testing.T) {
 	data := &types.AircraftData{
 		ICAO:     "B738",
@@ -288,6 +407,14 @@ func TestFetchRequestToQueryParams(t *testing.T) {
 			},
 			contains: []string{"userid=123456", "static_id=TEST_FLIGHT", "json=1"},
 		},
+		{
+			name: "user ID with API key",
+			request: &types.FetchRequest{
+				UserID: "123456",
+				APIKey: "secret",
+			},
+			contains: []string{"userid=123456", "api_key=secret"},
+		},
 		{
 			name:    "empty request",
 			request: &types.FetchRequest{},
@@ -310,3 +437,2262 @@ func TestFetchRequestToQueryParams(t *testing.T) {
 		})
 	}
 }
+
+func TestCalculateTakeoffWeight(t *testing.T) {
+	data := &types.AircraftData{
+		OEW:    90.0,
+		MTOW:   174.2,
+		PaxWgt: 200,
+	}
+
+	tow, err := types.CalculateTakeoffWeight(data, 150, 5.0, 40.0)
+	require.NoError(t, err)
+	assert.InDelta(t, 165.0, tow, 0.001)
+
+	tow, err = types.CalculateTakeoffWeight(data, 180, 10.0, 45.0)
+	assert.ErrorIs(t, err, types.ErrExceedsMTOW)
+	assert.InDelta(t, 181.0, tow, 0.001)
+
+	_, err = types.CalculateTakeoffWeight(nil, 100, 0, 0)
+	assert.ErrorIs(t, err, types.ErrMissingAircraftData)
+}
+
+func TestToURLValuesEmitsZeroDepartureMinute(t *testing.T) {
+	request := NewFlightPlan("KJFK", "KLAX", "B738").
+		DepartureTime(14, 0).
+		Build()
+
+	values := request.ToURLValues()
+
+	assert.Equal(t, "14", values.Get("deph"))
+	assert.Equal(t, "0", values.Get("depm"))
+	assert.False(t, values.Has("steh"))
+	assert.False(t, values.Has("stem"))
+}
+
+func TestToURLValuesEmitsZeroTaxiIn(t *testing.T) {
+	request := NewFlightPlan("KJFK", "KLAX", "B738").
+		TaxiTimes(12, 0).
+		Build()
+
+	values := request.ToURLValues()
+
+	assert.Equal(t, "12", values.Get("taxiout"))
+	assert.Equal(t, "0", values.Get("taxiin"))
+
+	values = NewFlightPlan("KJFK", "KLAX", "B738").Build().ToURLValues()
+	assert.False(t, values.Has("taxiout"))
+	assert.False(t, values.Has("taxiin"))
+}
+
+func TestDownloadMaps(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/xml.fetcher.php":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{
+				"origin": {"icao_code": "KJFK"},
+				"images": {
+					"directory": "%s/ofp/uads/",
+					"map": [
+						{"name": "Route", "link": "KJFKKLAX_ROUTE.gif"},
+						{"name": "Vertical profile", "link": "KJFKKLAX_VERT.gif"}
+					]
+				}
+			}`, server.URL)
+		case "/ofp/uads/KJFKKLAX_ROUTE.gif", "/ofp/uads/KJFKKLAX_VERT.gif":
+			w.Header().Set("Content-Type", "image/gif")
+			w.Write([]byte("GIF89a"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithConfig(server.URL, nil)
+
+	plan, err := client.GetFlightPlanByUserID("123456")
+	require.NoError(t, err)
+	require.Len(t, plan.Images.Maps, 2)
+	assert.Equal(t, "Route", plan.Images.Maps[0].Name)
+	assert.Equal(t, server.URL+"/ofp/uads/KJFKKLAX_ROUTE.gif", plan.Images.Maps[0].URL(plan.Images.Directory))
+
+	maps, err := client.DownloadMaps(context.Background(), plan)
+	require.NoError(t, err)
+	require.Len(t, maps, 2)
+	assert.Equal(t, "image/gif", maps[0].ContentType)
+	assert.Equal(t, []byte("GIF89a"), maps[1].Data)
+
+	maps, err = client.DownloadMaps(context.Background(), &types.FlightPlanResponse{})
+	require.NoError(t, err)
+	assert.Empty(t, maps)
+}
+
+func TestDecodeMapShapes(t *testing.T) {
+	var plan types.FlightPlanResponse
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"images": {"directory": "https://www.simbrief.com/ofp/uads/", "map": {"name": "Route", "link": "KJFKKLAX_ROUTE.gif"}}
+	}`), &plan))
+	require.Len(t, plan.Images.Maps, 1)
+	assert.Equal(t, "Route", plan.Images.Maps[0].Name)
+
+	plan = types.FlightPlanResponse{}
+	require.NoError(t, json.Unmarshal([]byte(`{"images": {"directory": "", "map": {}}}`), &plan))
+	assert.Empty(t, plan.Images.Maps)
+}
+
+func TestFetchSendsAPIKey(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithConfig(server.URL, nil)
+
+	_, err := client.GetFlightPlanByUserID("123456")
+	require.NoError(t, err)
+	assert.NotContains(t, query, "api_key")
+
+	client.APIKey = "secret"
+	_, err = client.GetFlightPlanByUserID("123456")
+	require.NoError(t, err)
+	assert.Contains(t, query, "api_key=secret")
+	assert.Contains(t, query, "userid=123456")
+}
+
+func TestFetchFlightPlanHTMLError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+		w.Write([]byte(`<!DOCTYPE html>
+<html>
+<head><title>SimBrief - Error: No flight plan found</title></head>
+<body><h1>Something went wrong</h1></body>
+</html>`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithConfig(server.URL, nil)
+
+	_, err := client.fetchFlightPlan(&types.FetchRequest{UserID: "123456", JSON: true})
+	require.Error(t, err)
+
+	var apiErr types.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "SimBrief - Error: No flight plan found", apiErr.Message)
+	assert.Equal(t, http.StatusOK, apiErr.Code)
+}
+
+func TestFetchFlightPlanDataServedAsHTML(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+		if r.URL.Query().Get("json") == "1" {
+			w.Write([]byte(`{"origin": {"icao_code": "KJFK"}}`))
+			return
+		}
+		w.Write([]byte(`<?xml version="1.0"?><OFP><origin><icao_code>KJFK</icao_code></origin></OFP>`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithConfig(server.URL, nil)
+
+	plan, err := client.fetchFlightPlan(&types.FetchRequest{UserID: "123456", JSON: true})
+	require.NoError(t, err)
+	assert.Equal(t, "KJFK", plan.Origin.ICAO)
+
+	plan, err = client.fetchFlightPlan(&types.FetchRequest{UserID: "123456"})
+	require.NoError(t, err)
+	assert.Equal(t, "KJFK", plan.Origin.ICAO)
+}
+
+func TestHTTPErrorPreservesStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("maintenance"))
+	}))
+	defer server.Close()
+
+	client := NewClientWithConfig(server.URL, nil)
+	client.APIKey = "secret-key"
+
+	_, err := client.fetchFlightPlan(&types.FetchRequest{UserID: "123456", JSON: true})
+	require.Error(t, err)
+
+	var httpErr *types.HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, http.StatusServiceUnavailable, httpErr.StatusCode)
+	assert.Equal(t, "maintenance", httpErr.Body)
+	assert.Contains(t, httpErr.URL, "api_key=REDACTED")
+	assert.NotContains(t, err.Error(), "secret-key")
+
+	err = client.Ping(context.Background())
+	assert.ErrorIs(t, err, types.ErrAPIUnavailable)
+	require.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, http.StatusServiceUnavailable, httpErr.StatusCode)
+}
+
+func TestFlightNumberValidation(t *testing.T) {
+	assert.NoError(t, types.ValidateFlightNumber("1234"))
+	assert.NoError(t, types.ValidateFlightNumber("918A"))
+	assert.ErrorIs(t, types.ValidateFlightNumber("12345678"), types.ErrInvalidFlightNumber)
+	assert.ErrorIs(t, types.ValidateFlightNumber("12-4"), types.ErrInvalidFlightNumber)
+
+	builder := NewFlightPlan("KJFK", "KLAX", "B738").FlightNumber("1234")
+	assert.Empty(t, builder.Errors())
+
+	builder = NewFlightPlan("KJFK", "KLAX", "B738").FlightNumber("12345678")
+	assert.Empty(t, builder.Build().FlightNumber)
+	require.Len(t, builder.Errors(), 1)
+	assert.ErrorIs(t, builder.Errors()[0], types.ErrInvalidFlightNumber)
+
+	builder = NewFlightPlan("KJFK", "KLAX", "B738").NormalizedFlightNumber(" 918a ", 4)
+	assert.Empty(t, builder.Errors())
+	assert.Equal(t, "0918A", builder.Build().FlightNumber)
+
+	builder = NewFlightPlan("KJFK", "KLAX", "B738").NormalizedFlightNumber("12-4", 0)
+	assert.Empty(t, builder.Build().FlightNumber)
+	require.Len(t, builder.Errors(), 1)
+}
+
+func TestNormalizeFlightNumber(t *testing.T) {
+	assert.Equal(t, "918", types.NormalizeFlightNumber("0918", 0))
+	assert.Equal(t, "0918", types.NormalizeFlightNumber("918", 4))
+	assert.Equal(t, "0012A", types.NormalizeFlightNumber("12a", 4))
+	assert.Equal(t, "0", types.NormalizeFlightNumber("000", 0))
+	assert.Equal(t, "BA0123", types.NormalizeFlightNumber("ba123", 4))
+	assert.Equal(t, "BA12", types.NormalizeFlightNumber("BA0012", 0))
+	assert.Equal(t, "ABC", types.NormalizeFlightNumber("abc", 4))
+
+	builder := NewFlightPlan("KJFK", "KLAX", "B738").NormalizedFlightNumber("ba012", 0)
+	assert.Empty(t, builder.Errors())
+	assert.Equal(t, "BA12", builder.Build().FlightNumber)
+}
+
+func TestDecisionFuel(t *testing.T) {
+	plan := &types.FlightPlanResponse{
+		Fuel: types.FuelInfo{
+			Plan:        "24500",
+			Taxi:        "400",
+			Trip:        "17200",
+			Contingency: "860",
+			Alternate:   "2900",
+			Reserve:     "2600",
+			Extra:       "540",
+		},
+	}
+
+	fuel, err := types.DecisionFuel(plan)
+	require.NoError(t, err)
+	assert.Equal(t, 5500.0, fuel)
+
+	plan.Fuel.Alternate = ""
+	_, err = types.DecisionFuel(plan)
+	assert.ErrorIs(t, err, types.ErrNoAlternate)
+}
+
+func TestEachFix(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"navlog": {
+				"fix": [
+					{"ident": "HAPIE", "type": "wpt", "pos_lat": "40.1", "pos_long": "-73.2", "altitude_feet": "12000"},
+					{"ident": "TOC", "type": "ltlg", "pos_lat": "40.5", "pos_long": "-75.0", "altitude_feet": "35000"},
+					{"ident": "COATE", "type": "vor", "pos_lat": 41.2, "pos_long": -76.8, "altitude_feet": 35000}
+				]
+			}
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithConfig(server.URL, nil)
+	plan, err := client.GetFlightPlanByUserID("123456")
+	require.NoError(t, err)
+
+	var idents []string
+	err = plan.EachFix(func(i int, f types.NavLogFix) error {
+		idents = append(idents, f.Ident)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"HAPIE", "TOC", "COATE"}, idents)
+	assert.Equal(t, 35000, plan.NavLogFixes()[1].Altitude)
+	assert.Equal(t, -75.0, plan.NavLogFixes()[1].Longitude)
+
+	stop := fmt.Errorf("stop")
+	count := 0
+	err = plan.EachFix(func(i int, f types.NavLogFix) error {
+		count++
+		if f.Ident == "TOC" {
+			return stop
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 2, count)
+
+	empty := &types.FlightPlanResponse{}
+	err = empty.EachFix(func(i int, f types.NavLogFix) error {
+		t.Fatal("callback must not be called")
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+func TestTopOfClimbAndDescent(t *testing.T) {
+	plan := &types.FlightPlanResponse{
+		NavLog: &types.NavLog{
+			Fixes: []types.NavLogFix{
+				{Ident: "HAPIE", Type: "wpt"},
+				{Ident: "TOC", Type: "ltlg", Altitude: 35000},
+				{Ident: "COATE", Type: "vor"},
+				{Ident: "ZZZZZ", Type: "TOD", Altitude: 35000},
+			},
+		},
+	}
+
+	toc, ok := plan.TopOfClimb()
+	require.True(t, ok)
+	assert.Equal(t, "TOC", toc.Ident)
+
+	tod, ok := plan.TopOfDescent()
+	require.True(t, ok)
+	assert.Equal(t, "ZZZZZ", tod.Ident)
+
+	empty := &types.FlightPlanResponse{}
+	toc, ok = empty.TopOfClimb()
+	assert.False(t, ok)
+	assert.Nil(t, toc)
+}
+
+func TestPayloadInUnits(t *testing.T) {
+	lbsPlan := &types.FlightPlanResponse{
+		General: types.GeneralInfo{Units: types.UnitsLBS},
+		Weights: types.WeightInfo{Payload: "38950"},
+	}
+	payload, units, err := lbsPlan.PayloadInUnits()
+	require.NoError(t, err)
+	assert.Equal(t, 38950.0, payload)
+	assert.Equal(t, types.UnitsLBS, units)
+
+	kgsPlan := &types.FlightPlanResponse{
+		Params:  types.FlightParams{Units: "kgs"},
+		Weights: types.WeightInfo{Payload: "17668"},
+	}
+	payload, units, err = kgsPlan.PayloadInUnits()
+	require.NoError(t, err)
+	assert.Equal(t, 17668.0, payload)
+	assert.Equal(t, types.UnitsKGS, units)
+
+	_, _, err = (&types.FlightPlanResponse{Weights: types.WeightInfo{Payload: "100"}}).PayloadInUnits()
+	assert.Error(t, err)
+}
+
+func TestPing(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/inputs.list.json", r.URL.Path)
+		w.WriteHeader(status)
+	}))
+
+	client := NewClientWithConfig(server.URL, nil)
+	assert.NoError(t, client.Ping(context.Background()))
+
+	status = http.StatusServiceUnavailable
+	err := client.Ping(context.Background())
+	assert.ErrorIs(t, err, types.ErrAPIUnavailable)
+	assert.Contains(t, err.Error(), "503")
+
+	server.Close()
+	assert.ErrorIs(t, client.Ping(context.Background()), types.ErrAPIUnavailable)
+}
+
+func TestContentHash(t *testing.T) {
+	newPlan := func(timeGen, requestID string) *types.FlightPlanResponse {
+		return &types.FlightPlanResponse{
+			Params:  types.FlightParams{TimeGen: timeGen, RequestID: requestID},
+			General: types.GeneralInfo{Route: "HAPIE J174 COATE", CruiseAltitude: "35000"},
+			Fuel:    types.FuelInfo{Plan: "24500", Trip: "17200"},
+			Weights: types.WeightInfo{TakeoffWt: "160000"},
+		}
+	}
+
+	a := newPlan("1700000000", "111")
+	b := newPlan("1700003600", "222")
+	assert.Equal(t, a.ContentHash(), b.ContentHash())
+
+	b.Fuel.Plan = "25000"
+	assert.NotEqual(t, a.ContentHash(), b.ContentHash())
+}
+
+func TestCustomEndpoints(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithConfig(server.URL, nil)
+	client.Endpoints.XMLFetcher = "/simbrief/fetch"
+	client.Endpoints.Generate = "/simbrief/dispatch"
+
+	_, err := client.GetFlightPlanByUserID("123456")
+	require.NoError(t, err)
+	assert.Equal(t, "/simbrief/fetch", path)
+
+	assert.Contains(t, client.GenerateFlightPlanURL(types.NewFlightPlanRequest("KJFK", "KLAX", "B738")),
+		server.URL+"/simbrief/dispatch?")
+
+	// Zero-value endpoints fall back to the defaults
+	bare := &Client{BaseURL: server.URL, HTTPClient: http.DefaultClient}
+	_, err = bare.GetFlightPlanByUserID("123456")
+	require.NoError(t, err)
+	assert.Equal(t, "/api/xml.fetcher.php", path)
+}
+
+func TestDecodeDestinationApproach(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"origin": {"icao_code": "KJFK", "plan_rwy": "31L"},
+			"destination": {"icao_code": "KLAX", "plan_rwy": "24R", "approach": "ILS24R", "transition": "SLI"}
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithConfig(server.URL, nil)
+	plan, err := client.GetFlightPlanByUserID("123456")
+	require.NoError(t, err)
+
+	assert.Equal(t, "ILS24R", plan.Destination.Approach)
+	assert.Equal(t, "SLI", plan.Destination.Transition)
+	assert.Empty(t, plan.Origin.Approach)
+	assert.Empty(t, plan.Origin.Transition)
+}
+
+func TestFlightPlanRequestSaveAndLoad(t *testing.T) {
+	original := NewFlightPlan("KJFK", "KLAX", "B738").
+		Route("HAPIE6 HAPIE J174 COATE").
+		DepartureTime(14, 0).
+		TaxiTimes(10, 0).
+		Cargo(5.5).
+		EnableNavLog().
+		Units(types.UnitsKGS).
+		CustomAircraftData(&types.AircraftData{ICAO: "B738", OEW: 90.7}).
+		Build()
+
+	var buf bytes.Buffer
+	require.NoError(t, original.Save(&buf))
+	assert.Contains(t, buf.String(), `"orig": "KJFK"`)
+	assert.Contains(t, buf.String(), `"acdata": {`)
+
+	restored, err := types.LoadFlightPlanRequest(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, original, restored)
+	assert.Equal(t, original.ToURLValues(), restored.ToURLValues())
+
+	_, err = types.LoadFlightPlanRequest(bytes.NewBufferString("not json"))
+	assert.Error(t, err)
+}
+
+func TestFlightPlanRequestMergeUnits(t *testing.T) {
+	base := NewFlightPlan("KJFK", "KLAX", "B738").
+		Units(types.UnitsLBS).
+		Cargo(10.0).
+		Route("HAPIE J174 COATE").
+		Build()
+	base.ManualZFW = 130.0
+
+	override := &types.FlightPlanRequest{
+		FlightNumber: "918",
+		Units:        types.UnitsKGS,
+		ManualZFW:    60.0,
+	}
+
+	merged, err := base.Merge(override)
+	require.NoError(t, err)
+	assert.Equal(t, types.UnitsKGS, merged.Units)
+	assert.Equal(t, "918", merged.FlightNumber)
+	assert.Equal(t, "HAPIE J174 COATE", merged.Route)
+	assert.Equal(t, 60.0, merged.ManualZFW)     // taken from the override as-is
+	assert.Equal(t, 4.536, merged.Cargo)        // converted from 10.0 LBS
+	assert.Equal(t, types.UnitsLBS, base.Units) // base is unchanged
+	assert.Equal(t, 10.0, base.Cargo)
+
+	unitless := &types.FlightPlanRequest{Origin: "KJFK", Cargo: 10.0}
+	_, err = unitless.Merge(&types.FlightPlanRequest{Units: types.UnitsKGS})
+	assert.Error(t, err)
+}
+
+func TestFlightPlanRequestFromValues(t *testing.T) {
+	original := NewFlightPlan("KJFK", "KLAX", "B738").
+		Route("HAPIE6 HAPIE J174 COATE").
+		DepartureTime(14, 0).
+		Passengers(150).
+		Cargo(5.5).
+		EnableNavLog().
+		DisableNavLog().
+		Units(types.UnitsKGS).
+		CustomAircraftData(&types.AircraftData{ICAO: "B738", OEW: 90.7}).
+		Build()
+
+	values := original.ToURLValues()
+	parsed, err := types.FlightPlanRequestFromValues(values)
+	require.NoError(t, err)
+	assert.Equal(t, original, parsed)
+
+	// Unknown keys are kept in Extra and sent again by ToURLValues
+	values.Set("unknown_param", "kept")
+	parsed, err = types.FlightPlanRequestFromValues(values)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"unknown_param": "kept"}, parsed.Extra)
+	assert.Equal(t, values, parsed.ToURLValues())
+
+	// Credentials from a shared dispatch URL are never kept
+	values.Set("api_key", "secret")
+	values.Set("userid", "123456")
+	parsed, err = types.FlightPlanRequestFromValues(values)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"unknown_param": "kept"}, parsed.Extra)
+	assert.NotContains(t, NewClient().GenerateFlightPlanURL(parsed), "secret")
+
+	values.Set("pax", "many")
+	_, err = types.FlightPlanRequestFromValues(values)
+	assert.ErrorContains(t, err, "pax")
+}
+
+func TestDecodeSafeAltitude(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"general": {"route": "HAPIE J174 COATE", "enroute_safe_altitude": "12400"},
+			"navlog": {"fix": [
+				{"ident": "HAPIE", "mora": "3200"},
+				{"ident": "COATE", "mora": ""}
+			]}
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithConfig(server.URL, nil)
+	plan, err := client.GetFlightPlanByUserID("123456")
+	require.NoError(t, err)
+
+	assert.Equal(t, "12400", plan.General.SafeAltitude)
+	fixes := plan.NavLogFixes()
+	require.Len(t, fixes, 2)
+	assert.Equal(t, 3200, fixes[0].MORA)
+	assert.Equal(t, 0, fixes[1].MORA)
+}
+
+func TestNewFetchRequest(t *testing.T) {
+	req, err := types.NewFetchRequest(types.WithUserID("123456"), types.WithStaticID("TEST"), types.WithJSON())
+	require.NoError(t, err)
+	assert.Equal(t, "123456", req.UserID)
+	assert.Equal(t, "TEST", req.StaticID)
+	assert.True(t, req.JSON)
+
+	_, err = types.NewFetchRequest()
+	assert.ErrorIs(t, err, types.ErrMissingUserID)
+
+	_, err = types.NewFetchRequest(types.WithStaticID("TEST"))
+	assert.ErrorIs(t, err, types.ErrMissingUserID)
+
+	_, err = types.NewFetchRequest(types.WithUserID("123456"), types.WithUsername("testuser"))
+	assert.ErrorIs(t, err, types.ErrConflictingUserIDs)
+}
+
+func TestToURLValuesOmitsEmptyAircraftData(t *testing.T) {
+	request := NewFlightPlan("KJFK", "KLAX", "B738").
+		CustomAircraftData(&types.AircraftData{}).
+		Build()
+	assert.False(t, request.ToURLValues().Has("acdata"))
+
+	request = NewFlightPlan("KJFK", "KLAX", "B738").
+		CustomAircraftData(&types.AircraftData{MTOW: 174.2}).
+		Build()
+	assert.Equal(t, `{"mtow":174.2}`, request.ToURLValues().Get("acdata"))
+}
+
+func TestToURLValuesUsername(t *testing.T) {
+	request := NewFlightPlan("KJFK", "KLAX", "B738").Build()
+	assert.False(t, request.ToURLValues().Has("username"))
+
+	request = NewFlightPlan("KJFK", "KLAX", "B738").Username("jdoe").Build()
+	assert.Equal(t, "jdoe", request.ToURLValues().Get("username"))
+	assert.Contains(t, NewClient().GenerateFlightPlanURL(request), "username=jdoe")
+}
+
+func TestFlightPlanBuilderClone(t *testing.T) {
+	template := NewFlightPlan("KJFK", "KLAX", "B738").
+		Airline("UAL").
+		FlightNumber("100").
+		Registration("N10001").
+		DepartureTime(14, 30).
+		EnableNavLog().
+		CustomAircraftData(&types.AircraftData{ICAO: "B738", OEW: 90.7})
+
+	clone := template.Clone().
+		FlightNumber("200").
+		Registration("N20002").
+		DepartureTime(16, 0).
+		DisableNavLog()
+	clone.Build().AircraftData.OEW = 91.5
+
+	original := template.Build()
+	assert.Equal(t, "100", original.FlightNumber)
+	assert.Equal(t, "N10001", original.Registration)
+	assert.Equal(t, 14, *original.DepartureHour)
+	assert.True(t, *original.NavLog)
+	assert.Equal(t, 90.7, original.AircraftData.OEW)
+
+	cloned := clone.Build()
+	assert.Equal(t, "200", cloned.FlightNumber)
+	assert.Equal(t, "UAL", cloned.Airline)
+	assert.Equal(t, 91.5, cloned.AircraftData.OEW)
+}
+
+func TestRawNavLog(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"navlog": {"fix": [
+				{"ident": "HAPIE", "stage": "CLB", "ind_airspeed": "290"},
+				{"ident": "COATE", "stage": "CRZ", "ind_airspeed": "271"}
+			]}
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithConfig(server.URL, nil)
+	plan, err := client.GetFlightPlanByUserID("123456")
+	require.NoError(t, err)
+	require.Len(t, plan.NavLogFixes(), 2)
+
+	var raw struct {
+		Fix []struct {
+			Ident       string `json:"ident"`
+			Stage       string `json:"stage"`
+			IndAirspeed string `json:"ind_airspeed"`
+		} `json:"fix"`
+	}
+	require.NoError(t, json.Unmarshal(plan.RawNavLog(), &raw))
+	require.Len(t, raw.Fix, 2)
+	assert.Equal(t, "CLB", raw.Fix[0].Stage)
+	assert.Equal(t, "271", raw.Fix[1].IndAirspeed)
+
+	assert.Nil(t, (&types.FlightPlanResponse{}).RawNavLog())
+	assert.Nil(t, plan.RawNavLogXML())
+
+	var xmlPlan types.FlightPlanResponse
+	require.NoError(t, xml.Unmarshal([]byte(`<OFP><navlog>
+		<fix><ident>HAPIE</ident><stage>CLB</stage></fix>
+		<fix><ident>COATE</ident><stage>CRZ</stage></fix>
+	</navlog></OFP>`), &xmlPlan))
+	require.Len(t, xmlPlan.NavLogFixes(), 2)
+	assert.Nil(t, xmlPlan.RawNavLog())
+
+	var rawXML struct {
+		Fix []struct {
+			Stage string `xml:"stage"`
+		} `xml:"fix"`
+	}
+	require.NoError(t, xml.Unmarshal(xmlPlan.RawNavLogXML(), &rawXML))
+	require.Len(t, rawXML.Fix, 2)
+	assert.Equal(t, "CRZ", rawXML.Fix[1].Stage)
+}
+
+func TestAlternateETEFromDest(t *testing.T) {
+	plan := &types.FlightPlanResponse{
+		Alternate: types.AlternateInfo{ICAO: "KLAS", Distance: "210"},
+	}
+
+	ete, err := types.AlternateETEFromDest(plan, 420)
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Minute, ete)
+
+	_, err = types.AlternateETEFromDest(plan, 0)
+	assert.Error(t, err)
+
+	_, err = types.AlternateETEFromDest(&types.FlightPlanResponse{}, 420)
+	assert.ErrorIs(t, err, types.ErrNoAlternate)
+}
+
+func TestStrictValidationAlternates(t *testing.T) {
+	client := NewClient()
+	request := &types.FlightPlanRequest{
+		Origin:      "KJFK",
+		Destination: "KLAX",
+		Aircraft:    "B738",
+		Alternate:   "KLAX",
+	}
+
+	// Not checked unless strict validation is enabled
+	assert.NoError(t, client.ValidateFlightPlanRequest(request))
+
+	client.StrictValidation = true
+	err := client.ValidateFlightPlanRequest(request)
+	assert.EqualError(t, err, "alternate altn (KLAX) must differ from destination")
+
+	request.Alternate = "KLAS"
+	request.Altn2ID = "kjfk"
+	err = client.ValidateFlightPlanRequest(request)
+	assert.EqualError(t, err, "alternate altn_2_id (kjfk) must differ from origin")
+
+	request.Altn2ID = "KONT"
+	assert.NoError(t, client.ValidateFlightPlanRequest(request))
+}
+
+func TestStrictValidationFuelPolicy(t *testing.T) {
+	client := NewClient()
+	client.FuelPolicy = &types.FuelPolicy{MinContingencyPct: 0.03, MaxContingencyPct: 0.10, MinReserveMinutes: 30}
+	request := &types.FlightPlanRequest{
+		Origin:      "KJFK",
+		Destination: "KLAX",
+		Aircraft:    "B738",
+		ContFuelPct: "0.15/10",
+		ReserveFuel: 20,
+	}
+
+	// Not checked unless strict validation is enabled
+	assert.NoError(t, client.ValidateFlightPlanRequest(request))
+
+	client.StrictValidation = true
+	err := client.ValidateFlightPlanRequest(request)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exceeds the policy maximum of 0.1")
+	assert.Contains(t, err.Error(), "reserve of 20 minutes is below the policy minimum of 30")
+
+	request.ContFuelPct = "0.05/15"
+	request.ReserveFuel = 45
+	assert.NoError(t, client.ValidateFlightPlanRequest(request))
+
+	request.ContFuelPct = ""
+	assert.ErrorContains(t, client.ValidateFlightPlanRequest(request), "requires a contingency")
+
+	cf, err := types.ParseContingencyFuel("0.05/15")
+	require.NoError(t, err)
+	assert.Equal(t, types.ContingencyFuel{Fraction: 0.05, MinMinutes: 15}, cf)
+	_, err = types.ParseContingencyFuel("5%")
+	assert.Error(t, err)
+}
+
+func TestNormalizeIdentifiers(t *testing.T) {
+	assert.Equal(t, "N123XX", types.NormalizeRegistration(" n123 xx"))
+	assert.Equal(t, "G-ABCD", types.NormalizeRegistration("g-abcd"))
+	assert.Equal(t, "UAL918", types.NormalizeCallsign("ual 918"))
+	assert.Equal(t, "ABCD", types.NormalizeSELCAL("ab-cd"))
+
+	request := NewFlightPlan(" kjfk", "klax ", "b738").
+		Registration("n39 max").
+		CallSign("ual-918").
+		SELCAL("ab-cd").
+		Alternate("klas").
+		Normalize().
+		Build()
+
+	assert.Equal(t, "KJFK", request.Origin)
+	assert.Equal(t, "KLAX", request.Destination)
+	assert.Equal(t, "B738", request.Aircraft)
+	assert.Equal(t, "N39MAX", request.Registration)
+	assert.Equal(t, "UAL918", request.ATCCallsign)
+	assert.Equal(t, "ABCD", request.SELCAL)
+	assert.Equal(t, "KLAS", request.Alternate)
+}
+
+func TestDecodeRemarks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"general": {
+				"dx_rmk": "CREW MEAL REQUESTED",
+				"sys_rmk": ["FUEL BIAS APPLIED", "ALTERNATE WX BELOW MINIMA"]
+			}
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithConfig(server.URL, nil)
+	plan, err := client.GetFlightPlanByUserID("123456")
+	require.NoError(t, err)
+	assert.Equal(t, "CREW MEAL REQUESTED", plan.General.DispatcherNotes.String())
+	assert.Equal(t, "FUEL BIAS APPLIED\nALTERNATE WX BELOW MINIMA", plan.General.SystemRemarks.String())
+
+	var empty types.GeneralInfo
+	require.NoError(t, json.Unmarshal([]byte(`{"dx_rmk": {}, "sys_rmk": {}}`), &empty))
+	assert.Empty(t, empty.DispatcherNotes.Value)
+	assert.Empty(t, empty.SystemRemarks.Value)
+
+	var fromXML types.GeneralInfo
+	require.NoError(t, xml.Unmarshal([]byte(`<general><dx_rmk>CREW MEAL</dx_rmk><sys_rmk>LINE 1</sys_rmk><sys_rmk>LINE 2</sys_rmk></general>`), &fromXML))
+	assert.Equal(t, "CREW MEAL", fromXML.DispatcherNotes.Value)
+	assert.Equal(t, "LINE 1\nLINE 2", fromXML.SystemRemarks.Value)
+}
+
+func TestLayoutMatches(t *testing.T) {
+	plan := &types.FlightPlanResponse{Params: types.FlightParams{OFPLayout: "LIDO"}}
+
+	assert.True(t, plan.LayoutMatches(string(types.PlanFormatLIDO)))
+	assert.True(t, plan.LayoutMatches("lido"))
+	assert.True(t, plan.LayoutMatches(""))
+	assert.False(t, plan.LayoutMatches("AAL"))
+}
+
+func TestDiffFlightPlans(t *testing.T) {
+	a := &types.FlightPlanResponse{
+		Params:  types.FlightParams{RequestID: "1", TimeGen: "1700000000"},
+		General: types.GeneralInfo{Route: "HAPIE J174 COATE"},
+		Fuel:    types.FuelInfo{Plan: "24500", Trip: "17200"},
+		Weights: types.WeightInfo{TakeoffWt: "160000"},
+	}
+	b := &types.FlightPlanResponse{
+		Params:  types.FlightParams{RequestID: "2", TimeGen: "1700003600"},
+		General: types.GeneralInfo{Route: "HAPIE J174 COATE"},
+		Fuel:    types.FuelInfo{Plan: "25100", Trip: "17200"},
+		Weights: types.WeightInfo{TakeoffWt: "160600"},
+	}
+
+	diffs := types.DiffFlightPlans(a, b)
+	assert.Equal(t, []types.FieldDiff{
+		{Path: "Fuel.Plan", Old: "24500", New: "25100"},
+		{Path: "Weights.TakeoffWt", Old: "160000", New: "160600"},
+	}, diffs)
+
+	diffs = types.DiffFlightPlans(a, b, types.IncludeVolatile())
+	require.Len(t, diffs, 4)
+	assert.Equal(t, "Params.RequestID", diffs[0].Path)
+	assert.Equal(t, "Params.TimeGen", diffs[1].Path)
+
+	assert.Empty(t, types.DiffFlightPlans(a, a))
+
+	a.Alternates = []types.AlternateInfo{{ICAO: "EGKK"}}
+	b.Alternates = []types.AlternateInfo{{ICAO: "EGKK"}, {ICAO: "EGSS"}}
+	diffs = types.DiffFlightPlans(a, b)
+	assert.Contains(t, diffs, types.FieldDiff{Path: "Alternates[1].ICAO", Old: "", New: "EGSS"})
+}
+
+func TestCheckLandingFuel(t *testing.T) {
+	fuel := types.FuelInfo{
+		Plan:        "24500",
+		Taxi:        "400",
+		Trip:        "17200",
+		PlanLanding: " 6900 ",
+	}
+
+	landing, err := fuel.LandingFloat()
+	require.NoError(t, err)
+	assert.Equal(t, 6900.0, landing)
+
+	landing, err = fuel.CheckLandingFuel(1)
+	require.NoError(t, err)
+	assert.Equal(t, 6900.0, landing)
+
+	fuel.PlanLanding = "8400"
+	_, err = fuel.CheckLandingFuel(1)
+	assert.ErrorIs(t, err, types.ErrFuelDiscrepancy)
+
+	fuel.PlanLanding = ""
+	_, err = fuel.LandingFloat()
+	assert.Error(t, err)
+}
+
+func TestFlightPlanBuilderRemarks(t *testing.T) {
+	builder := NewFlightPlan("KJFK", "KLAX", "B738").
+		Remarks("CREW MEAL REQUESTED", "  ", "WHEELCHAIR PAX 12C ").
+		AddRemark("VIP ON BOARD").
+		AddRemark("")
+
+	request := builder.Build()
+	assert.Equal(t, "CREW MEAL REQUESTED\nWHEELCHAIR PAX 12C\nVIP ON BOARD", request.ManualRemarks)
+
+	url := NewClient().GenerateFlightPlanURL(request)
+	assert.Contains(t, url, "manualrmk=CREW+MEAL+REQUESTED%0AWHEELCHAIR+PAX+12C%0AVIP+ON+BOARD")
+}
+
+func TestFetchRouteCoordinates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/ofp/flightplans/KJFKKLAX.kml", r.URL.Path)
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<kml xmlns="http://www.opengis.net/kml/2.2">
+  <Document>
+    <Placemark><name>KJFK</name><Point><coordinates>-73.7789,40.6398,0</coordinates></Point></Placemark>
+    <Placemark>
+      <LineString>
+        <coordinates>
+          -73.7789,40.6398,0
+          -76.8,41.2,35000
+          -118.4081,33.9425,0
+        </coordinates>
+      </LineString>
+    </Placemark>
+  </Document>
+</kml>`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithConfig(server.URL, nil)
+	plan := &types.FlightPlanResponse{
+		Files: types.FilesInfo{
+			Directory: server.URL + "/ofp/flightplans/",
+			KMLLink:   map[string]interface{}{"name": "Google Earth KML", "link": "KJFKKLAX.kml"},
+		},
+	}
+
+	coords, err := client.FetchRouteCoordinates(context.Background(), plan)
+	require.NoError(t, err)
+	assert.Equal(t, []types.LatLon{
+		{Lat: 40.6398, Lon: -73.7789},
+		{Lat: 41.2, Lon: -76.8},
+		{Lat: 33.9425, Lon: -118.4081},
+	}, coords)
+
+	plan.Files.KMLLink = false
+	_, err = client.FetchRouteCoordinates(context.Background(), plan)
+	assert.ErrorIs(t, err, types.ErrNoKMLFile)
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    float64
+		wantErr bool
+	}{
+		{input: "10,000.5", want: 10000.5},
+		{input: " 24500 ", want: 24500},
+		{input: "1 234 567", want: 1234567},
+		{input: "-12.5", want: -12.5},
+		{input: "", wantErr: true},
+		{input: "N/A", wantErr: true},
+		{input: "1,234,567", want: 1234567},
+		{input: "10,5", wantErr: true},
+		{input: "1,0000", wantErr: true},
+		{input: "1.000,5", wantErr: true},
+		{input: ",500", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := types.ParseNumber(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	fuel := types.FuelInfo{PlanLanding: "6,900"}
+	landing, err := fuel.LandingFloat()
+	require.NoError(t, err)
+	assert.Equal(t, 6900.0, landing)
+}
+
+func TestDecodeEnrouteAlternates(t *testing.T) {
+	var plan types.FlightPlanResponse
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"enroute_altn": [
+			{"icao_code": "CYQX", "name": "GANDER INTL"},
+			{"icao_code": "BIKF", "name": "KEFLAVIK"}
+		]
+	}`), &plan))
+	alternates := plan.EnrouteAlternates()
+	require.Len(t, alternates, 2)
+	assert.Equal(t, "CYQX", alternates[0].ICAO)
+	assert.Equal(t, "KEFLAVIK", alternates[1].Name)
+
+	var single types.FlightPlanResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"enroute_altn": {"icao_code": "LPLA"}}`), &single))
+	require.Len(t, single.EnrouteAlternates(), 1)
+	assert.Equal(t, "LPLA", single.EnrouteAlternates()[0].ICAO)
+
+	var fromXML types.FlightPlanResponse
+	require.NoError(t, xml.Unmarshal([]byte(`<OFP><enroute_altn><icao_code>CYQX</icao_code></enroute_altn><enroute_altn><icao_code>BIKF</icao_code></enroute_altn></OFP>`), &fromXML))
+	assert.Len(t, fromXML.EnrouteAlternates(), 2)
+
+	var none types.FlightPlanResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"origin": {"icao_code": "KJFK"}}`), &none))
+	assert.Empty(t, none.EnrouteAlternates())
+}
+
+func TestGetFlightPlanByUsernameAndStaticID(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithConfig(server.URL, nil)
+
+	_, err := client.GetFlightPlanByUsernameAndStaticID("testuser", "TEST_FLIGHT")
+	require.NoError(t, err)
+	assert.Equal(t, "testuser", query.Get("username"))
+	assert.Equal(t, "TEST_FLIGHT", query.Get("static_id"))
+	assert.False(t, query.Has("userid"))
+
+	query = nil
+	_, err = client.GetFlightPlanByStaticID("", "TEST_FLIGHT")
+	assert.ErrorIs(t, err, types.ErrMissingUserID)
+	assert.Nil(t, query, "no request should be sent without a user identifier")
+}
+
+func TestFlightPlanSummary(t *testing.T) {
+	plan := &types.FlightPlanResponse{
+		Origin:      types.AirportInfo{ICAO: "KJFK"},
+		Destination: types.AirportInfo{ICAO: "KLAX"},
+		Aircraft:    types.AircraftInfo{ICAO: "B738"},
+		General:     types.GeneralInfo{Route: "HAPIE J174 COATE", Distance: "2,145", Units: types.UnitsLBS},
+		Times:       types.TimeInfo{BlockTime: "22620"},
+		Fuel:        types.FuelInfo{Plan: "38500"},
+		Weights:     types.WeightInfo{PaxCount: "150"},
+	}
+
+	summary := plan.Summary()
+	assert.Equal(t, "KJFK", summary.Origin)
+	assert.Equal(t, "KLAX", summary.Destination)
+	assert.Equal(t, 2145.0, summary.DistanceNM)
+	assert.Equal(t, 6*time.Hour+17*time.Minute, summary.BlockTime)
+	assert.Equal(t, 38500.0, summary.RampFuel)
+	assert.Equal(t, 150, summary.PaxCount)
+	assert.Empty(t, summary.Warnings)
+
+	plan.Weights.PaxCount = " 1,050 "
+	assert.Equal(t, 1050, plan.Summary().PaxCount)
+
+	plan.Weights.PaxCount = "many"
+	plan.Times.BlockTime = ""
+	summary = plan.Summary()
+	assert.Equal(t, 0, summary.PaxCount)
+	assert.Equal(t, time.Duration(0), summary.BlockTime)
+	assert.Len(t, summary.Warnings, 2)
+}
+
+func TestFlightPlanBuilderScheduledEnrouteTime(t *testing.T) {
+	builder := NewFlightPlan("KJFK", "KLAX", "B738").
+		ScheduledEnrouteTime(2*time.Hour + 45*time.Minute)
+
+	values := builder.Build().ToURLValues()
+	assert.Equal(t, "2", values.Get("steh"))
+	assert.Equal(t, "45", values.Get("stem"))
+	assert.Empty(t, builder.Errors())
+
+	builder = NewFlightPlan("KJFK", "KLAX", "B738").ScheduledEnrouteTime(25 * time.Hour)
+	assert.Nil(t, builder.Build().ScheduledHour)
+	assert.Len(t, builder.Errors(), 1)
+
+	builder = NewFlightPlan("KJFK", "KLAX", "B738").ScheduledEnrouteTime(-time.Minute)
+	assert.Len(t, builder.Errors(), 1)
+}
+
+func TestAverageHeadwind(t *testing.T) {
+	plan := &types.FlightPlanResponse{
+		NavLog: &types.NavLog{
+			Fixes: []types.NavLogFix{
+				{Ident: "KJFK"}, // departure fix without a leg
+				{Ident: "A", Track: 270, Distance: 100, Wind: "270/40"}, // 40 kt headwind
+				{Ident: "B", Track: 270, Distance: 300, Wind: "09020"},  // 20 kt tailwind
+				{Ident: "C", Track: 270, Distance: 100, Wind: "00000"},  // calm
+			},
+		},
+	}
+
+	avg, err := plan.AverageHeadwind()
+	require.NoError(t, err)
+	// (40*100 - 20*300 + 0*100) / 500
+	assert.InDelta(t, -4.0, avg, 0.001)
+
+	_, err = (&types.FlightPlanResponse{}).AverageHeadwind()
+	assert.Error(t, err)
+}
+
+func TestComputedAvgFuelFlow(t *testing.T) {
+	plan := &types.FlightPlanResponse{
+		NavLog: &types.NavLog{
+			Fixes: []types.NavLogFix{
+				{Ident: "KJFK"},                            // departure fix without a leg
+				{Ident: "A", ETE: "600", FuelFlow: 8000},   // 10 min climb
+				{Ident: "B", ETE: "1800", FuelFlow: 5000},  // 30 min cruise
+				{Ident: "C", ETE: "600"},                   // no fuel flow, skipped
+				{Ident: "D", ETE: "00:20", FuelFlow: 2000}, // 20 min descent
+			},
+		},
+	}
+
+	avg, err := plan.ComputedAvgFuelFlow()
+	require.NoError(t, err)
+	// (8000*10 + 5000*30 + 2000*20) / 60
+	assert.InDelta(t, 4500.0, avg, 0.001)
+
+	_, err = (&types.FlightPlanResponse{}).ComputedAvgFuelFlow()
+	assert.Error(t, err)
+}
+
+func TestFlightPlanBuilderContingency(t *testing.T) {
+	builder := NewFlightPlan("KJFK", "KLAX", "B738").Contingency(0.05, 15)
+	assert.Empty(t, builder.Errors())
+	assert.Equal(t, "0.05/15", builder.Build().ContFuelPct)
+
+	builder = NewFlightPlan("KJFK", "KLAX", "B738").Contingency(0.03, 0)
+	assert.Equal(t, "0.03", builder.Build().ContFuelPct)
+
+	builder = NewFlightPlan("KJFK", "KLAX", "B738").Contingency(5, 10)
+	assert.Len(t, builder.Errors(), 1)
+	assert.Empty(t, builder.Build().ContFuelPct)
+}
+
+func TestAirportByCode(t *testing.T) {
+	plan := &types.FlightPlanResponse{
+		Origin:      types.AirportInfo{ICAO: "KJFK", IATA: "JFK"},
+		Destination: types.AirportInfo{ICAO: "EGLL", IATA: "LHR"},
+		Alternate:   types.AlternateInfo{ICAO: "EGKK", IATA: "LGW", Name: "Gatwick"},
+	}
+
+	airport, ok := plan.AirportByCode("lhr")
+	require.True(t, ok)
+	assert.Equal(t, "EGLL", airport.ICAO)
+
+	airport, ok = plan.AirportByCode("kjfk")
+	require.True(t, ok)
+	icao, iata := airport.Codes()
+	assert.Equal(t, "KJFK", icao)
+	assert.Equal(t, "JFK", iata)
+
+	airport, ok = plan.AirportByCode("LGW")
+	require.True(t, ok)
+	assert.Equal(t, "Gatwick", airport.Name)
+
+	_, ok = plan.AirportByCode("KLAX")
+	assert.False(t, ok)
+	_, ok = plan.AirportByCode("")
+	assert.False(t, ok)
+
+	plan = &types.FlightPlanResponse{
+		Alternates: []types.AlternateInfo{{ICAO: "EGKK"}, {ICAO: "EGSS", Name: "Stansted"}},
+	}
+	airport, ok = plan.AirportByCode("egss")
+	require.True(t, ok)
+	assert.Equal(t, "Stansted", airport.Name)
+}
+
+func TestDecodeAlternateWeather(t *testing.T) {
+	var plan types.FlightPlanResponse
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"alternate": {
+			"icao_code": "EGKK",
+			"metar_category": "MVFR",
+			"metar_visibility": "4000",
+			"metar_ceiling": "1200"
+		}
+	}`), &plan))
+	assert.Equal(t, "MVFR", plan.Alternate.MetarCategory)
+	assert.Equal(t, "4000", plan.Alternate.MetarVisibility)
+	assert.Equal(t, "1200", plan.Alternate.MetarCeiling)
+
+	var fromXML types.FlightPlanResponse
+	require.NoError(t, xml.Unmarshal([]byte(`<OFP><alternate><icao_code>EGKK</icao_code><metar_category>IFR</metar_category></alternate></OFP>`), &fromXML))
+	assert.Equal(t, "IFR", fromXML.Alternate.MetarCategory)
+	assert.Empty(t, fromXML.Alternate.MetarCeiling)
+}
+
+func TestCurlForGenerate(t *testing.T) {
+	client := NewClient()
+	client.APIKey = "secret-key"
+	req := NewFlightPlan("KJFK", "KLAX", "B738").Build()
+
+	cmd := client.CurlForGenerate(req)
+	assert.True(t, strings.HasPrefix(cmd, "curl "))
+	assert.Contains(t, cmd, DefaultBaseURL+"/system/dispatch.php?")
+	assert.Contains(t, cmd, "orig=KJFK")
+	assert.Contains(t, cmd, "api_key=REDACTED")
+	assert.NotContains(t, cmd, "secret-key")
+
+	assert.Contains(t, client.CurlForGenerate(req, RevealAPIKey()), "api_key=secret-key")
+}
+
+func TestDecodeMultipleAlternates(t *testing.T) {
+	var plan types.FlightPlanResponse
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"alternate": [
+			{"icao_code": "EGKK", "burn": "2100", "bearing": "160"},
+			{"icao_code": "EGSS", "burn": "2400", "bearing": "040"}
+		]
+	}`), &plan))
+	require.Len(t, plan.Alternates, 2)
+	assert.Equal(t, "EGSS", plan.Alternates[1].ICAO)
+	assert.Equal(t, "2400", plan.Alternates[1].FuelRequired)
+	assert.Equal(t, "EGKK", plan.Alternate.ICAO)
+
+	var single types.FlightPlanResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"alternate": {"icao_code": "EGKK"}}`), &single))
+	require.Len(t, single.Alternates, 1)
+	assert.Equal(t, "EGKK", single.Alternate.ICAO)
+
+	var fromXML types.FlightPlanResponse
+	require.NoError(t, xml.Unmarshal([]byte(`<OFP><alternate><icao_code>EGKK</icao_code><bearing>160</bearing></alternate><alternate><icao_code>EGSS</icao_code><bearing>040</bearing></alternate></OFP>`), &fromXML))
+	require.Len(t, fromXML.Alternates, 2)
+	assert.Equal(t, "040", fromXML.Alternates[1].Bearing)
+	assert.Equal(t, "EGKK", fromXML.Alternate.ICAO)
+}
+
+func TestGenerateFlightPlan(t *testing.T) {
+	generatePollInterval, generatePollMaxInterval = time.Millisecond, 2*time.Millisecond
+	defer func() { generatePollInterval, generatePollMaxInterval = time.Second, 8*time.Second }()
+
+	var generated bool
+	var fetchesAfterGenerate int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/system/dispatch.php":
+			assert.Equal(t, "test-key", r.URL.Query().Get("api_key"))
+			assert.Equal(t, "123456", r.URL.Query().Get("userid"))
+			assert.Equal(t, "MYPLAN", r.URL.Query().Get("static_id"))
+			generated = true
+		case "/api/xml.fetcher.php":
+			assert.Equal(t, "MYPLAN", r.URL.Query().Get("static_id"))
+			if generated {
+				fetchesAfterGenerate++
+			}
+			if fetchesAfterGenerate < 3 {
+				// Previous plan stored under the same static ID, then nothing while generating
+				if !generated {
+					fmt.Fprint(w, `{"params": {"request_id": "1"}}`)
+					return
+				}
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"fetch": {"status": "Error: No flight plan on file for the specified user"}}`)
+				return
+			}
+			fmt.Fprint(w, `{"params": {"request_id": "2"}, "origin": {"icao_code": "KJFK"}}`)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithConfig(server.URL, nil)
+	req := NewFlightPlan("KJFK", "KLAX", "B738").StaticID("MYPLAN").Build()
+
+	_, err := client.GenerateFlightPlan(context.Background(), req, "123456")
+	assert.ErrorIs(t, err, types.ErrInvalidAPIKey)
+
+	client.APIKey = "test-key"
+	plan, err := client.GenerateFlightPlan(context.Background(), req, "123456")
+	require.NoError(t, err)
+	assert.Equal(t, "2", plan.Params.RequestID)
+	assert.Equal(t, "KJFK", plan.Origin.ICAO)
+}
+
+func TestGenerateFlightPlanPermanentError(t *testing.T) {
+	generatePollInterval, generatePollMaxInterval = time.Millisecond, 2*time.Millisecond
+	defer func() { generatePollInterval, generatePollMaxInterval = time.Second, 8*time.Second }()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/xml.fetcher.php" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"fetch": {"status": "Error: Unknown UserID"}}`)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithConfig(server.URL, nil)
+	client.APIKey = "test-key"
+	req := NewFlightPlan("KJFK", "KLAX", "B738").Build()
+
+	_, err := client.GenerateFlightPlan(context.Background(), req, "123456")
+	assert.NotErrorIs(t, err, context.DeadlineExceeded, "permanent errors must not wait for the timeout")
+	var apiErr types.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "Error: Unknown UserID", apiErr.Message)
+}
+
+func TestGenerateFlightPlanTimeout(t *testing.T) {
+	generatePollInterval, generatePollMaxInterval = time.Millisecond, 2*time.Millisecond
+	defer func() { generatePollInterval, generatePollMaxInterval = time.Second, 8*time.Second }()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/xml.fetcher.php" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"fetch": {"status": "Error: No flight plan on file for the specified user"}}`)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithConfig(server.URL, nil)
+	client.APIKey = "test-key"
+	req := NewFlightPlan("KJFK", "KLAX", "B738").Build()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := client.GenerateFlightPlan(ctx, req, "123456")
+	var timeoutErr *types.GenerateTimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+	assert.NotEmpty(t, timeoutErr.StaticID)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Empty(t, req.StaticID, "caller request must not be modified")
+	var apiErr types.APIError
+	require.ErrorAs(t, timeoutErr.LastErr, &apiErr)
+	assert.Contains(t, err.Error(), "No flight plan on file")
+
+	// The wait is measured with the client clock
+	client.Clock = fixedClock(time.Unix(1700000000, 0))
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = client.GenerateFlightPlan(ctx, req, "123456")
+	require.ErrorAs(t, err, &timeoutErr)
+	assert.Zero(t, timeoutErr.Waited)
+}
+
+func TestDecodeSimInfo(t *testing.T) {
+	var plan types.FlightPlanResponse
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"sim": {"simulator": "MSFS", "version": "2024", "scenario": "KJFK-KLAX"}
+	}`), &plan))
+	assert.Equal(t, "MSFS", plan.Sim.Simulator)
+	assert.Equal(t, "2024", plan.Sim.Version)
+	assert.Equal(t, "KJFK-KLAX", plan.Sim.Scenario)
+
+	var fromXML types.FlightPlanResponse
+	require.NoError(t, xml.Unmarshal([]byte(`<OFP><sim><simulator>XP12</simulator></sim></OFP>`), &fromXML))
+	assert.Equal(t, "XP12", fromXML.Sim.Simulator)
+
+	var none types.FlightPlanResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"origin": {"icao_code": "KJFK"}}`), &none))
+	assert.Equal(t, types.SimInfo{}, none.Sim)
+}
+
+func TestValidateFuelMonotonic(t *testing.T) {
+	plan := &types.FlightPlanResponse{
+		NavLog: &types.NavLog{
+			Fixes: []types.NavLogFix{
+				{Ident: "KJFK", FuelRemain: 0},
+				{Ident: "HAPIE", FuelRemain: 1200},
+				{Ident: "COATE", FuelRemain: 1200},
+				{Ident: "KLAX", FuelRemain: 9800},
+			},
+		},
+	}
+	assert.NoError(t, plan.ValidateFuelMonotonic())
+
+	plan.NavLog.Fixes[2].FuelRemain = 900
+	err := plan.ValidateFuelMonotonic()
+	require.ErrorIs(t, err, types.ErrFuelDiscrepancy)
+	assert.Contains(t, err.Error(), "HAPIE")
+	assert.Contains(t, err.Error(), "COATE")
+
+	assert.NoError(t, (&types.FlightPlanResponse{}).ValidateFuelMonotonic())
+}
+
+func TestParsePlanFormat(t *testing.T) {
+	tests := []struct {
+		input string
+		want  types.PlanFormat
+		ok    bool
+	}{
+		{"LIDO", types.PlanFormatLIDO, true},
+		{" ual ", types.PlanFormatUAL, true},
+		{"ryr", types.PlanFormatRYR, true},
+		{"", types.PlanFormatDefault, true},
+		{"XYZ", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := types.ParsePlanFormat(tt.input)
+		assert.Equal(t, tt.ok, ok, tt.input)
+		assert.Equal(t, tt.want, got, tt.input)
+	}
+}
+
+const sampleOFPXML = `<?xml version="1.0" encoding="UTF-8"?>
+<OFP>
+	<params>
+		<request_id>98765432</request_id>
+		<user_id>857341</user_id>
+		<time_generated>1700000000</time_generated>
+		<static_id>MYFLIGHT</static_id>
+		<ofp_layout>LIDO</ofp_layout>
+		<units>kgs</units>
+	</params>
+	<general>
+		<icao_airline>UAL</icao_airline>
+		<flight_number>918</flight_number>
+		<route>HAPIE6 HAPIE N247A ALLRY</route>
+		<dx_rmk>CHECK NOTAMS</dx_rmk>
+		<dx_rmk>FUEL ADDED FOR WX</dx_rmk>
+	</general>
+	<aircraft>
+		<icaocode>B39M</icaocode>
+		<reg>N39MAX</reg>
+		<selcal>ABCD</selcal>
+		<maxpax>220</maxpax>
+	</aircraft>
+	<origin><icao_code>KJFK</icao_code><iata_code>JFK</iata_code></origin>
+	<destination><icao_code>EGLL</icao_code><iata_code>LHR</iata_code></destination>
+	<alternate><icao_code>EGKK</icao_code><burn>2100</burn></alternate>
+	<fuel><plan_ramp>45000</plan_ramp></fuel>
+	<navlog>
+		<fix><ident>HAPIE</ident><pos_lat>40.9</pos_lat><pos_long>-72.1</pos_long><distance_nm>75</distance_nm><wind>270/040</wind><fuel_totalused>1800</fuel_totalused></fix>
+		<fix><ident>ALLRY</ident><pos_lat>44.0</pos_lat><pos_long>-66.0</pos_long><distance_nm>310</distance_nm><wind>280/060</wind><fuel_totalused>5200</fuel_totalused></fix>
+	</navlog>
+	<files>
+		<directory>https://www.simbrief.com/ofp/flightplans/</directory>
+		<pdf><name>KJFKEGLL_PDF.pdf</name><link>KJFKEGLL_PDF.pdf</link></pdf>
+		<kml><name>KJFKEGLL.kml</name><link>KJFKEGLL.kml</link></kml>
+	</files>
+</OFP>`
+
+func TestDecodeXMLResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.URL.Query().Get("json"))
+		w.Header().Set("Content-Type", "application/xml")
+		fmt.Fprint(w, sampleOFPXML)
+	}))
+	defer server.Close()
+
+	client := NewClientWithConfig(server.URL, nil)
+	data, err := client.GetFlightPlanXML(&types.FetchRequest{UserID: "857341"})
+	require.NoError(t, err)
+
+	var plan types.FlightPlanResponse
+	require.NoError(t, xml.Unmarshal(data, &plan))
+
+	assert.Equal(t, "98765432", plan.Params.RequestID)
+	assert.Equal(t, "MYFLIGHT", plan.Params.StaticID.String())
+	units, err := plan.PlanUnits()
+	require.NoError(t, err)
+	assert.Equal(t, types.UnitsKGS, units)
+	assert.Equal(t, "CHECK NOTAMS\nFUEL ADDED FOR WX", plan.General.DispatcherNotes.String())
+	assert.Equal(t, "ABCD", plan.Aircraft.SELCAL.String())
+	assert.Equal(t, 220, plan.Aircraft.MaxPax)
+	assert.Equal(t, "LHR", plan.Destination.IATA)
+	assert.Equal(t, "EGKK", plan.Alternate.ICAO)
+
+	fixes := plan.NavLogFixes()
+	require.Len(t, fixes, 2)
+	assert.Equal(t, "ALLRY", fixes[1].Ident)
+	assert.InDelta(t, -66.0, fixes[1].Longitude, 0.001)
+	assert.Equal(t, 5200.0, fixes[1].FuelRemain)
+
+	kml, ok := plan.Files.KMLURL()
+	require.True(t, ok)
+	assert.Equal(t, "https://www.simbrief.com/ofp/flightplans/KJFKEGLL.kml", kml)
+	assert.NotNil(t, plan.Files.PDFLink)
+	assert.Nil(t, plan.Files.PLNLink)
+}
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time { return time.Time(c) }
+
+func TestClientIsStale(t *testing.T) {
+	generated := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	plan := &types.FlightPlanResponse{
+		Params: types.FlightParams{TimeGen: fmt.Sprint(generated.Unix())},
+	}
+
+	client := NewClient()
+	client.Clock = fixedClock(generated.Add(90 * time.Minute))
+
+	age, err := client.PlanAge(plan)
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Minute, age)
+
+	stale, err := client.IsStale(plan, 2*time.Hour)
+	require.NoError(t, err)
+	assert.False(t, stale)
+
+	stale, err = client.IsStale(plan, time.Hour)
+	require.NoError(t, err)
+	assert.True(t, stale)
+
+	_, err = client.IsStale(&types.FlightPlanResponse{}, time.Hour)
+	assert.Error(t, err)
+}
+
+func TestPhaseTimes(t *testing.T) {
+	var plan types.FlightPlanResponse
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"times": {"est_time_enroute": "3600"},
+		"navlog": {"fix": [
+			{"ident": "KJFK", "stage": "CLB", "time_leg": ""},
+			{"ident": "HAPIE", "stage": "CLB", "time_leg": "480"},
+			{"ident": "TOC", "stage": "CLB", "time_leg": "420"},
+			{"ident": "ALLRY", "stage": "CRZ", "time_leg": "1500"},
+			{"ident": "TOD", "stage": "CRZ", "time_leg": "300"},
+			{"ident": "KBOS", "stage": "DSC", "time_leg": "900"}
+		]}
+	}`), &plan))
+
+	climb, cruise, descent, err := plan.PhaseTimes()
+	require.NoError(t, err)
+	assert.Equal(t, 15*time.Minute, climb)
+	assert.Equal(t, 30*time.Minute, cruise)
+	assert.Equal(t, 15*time.Minute, descent)
+
+	total, err := strconv.Atoi(plan.Times.FlightTime)
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(total)*time.Second, climb+cruise+descent)
+
+	_, _, _, err = (&types.FlightPlanResponse{}).PhaseTimes()
+	assert.Error(t, err)
+}
+
+func TestAlternateFuelMargin(t *testing.T) {
+	plan := &types.FlightPlanResponse{
+		Fuel:      types.FuelInfo{Alternate: "2,400"},
+		Alternate: types.AlternateInfo{ICAO: "EGKK", FuelRequired: "2100"},
+	}
+	margin, err := plan.AlternateFuelMargin()
+	require.NoError(t, err)
+	assert.Equal(t, 300.0, margin)
+
+	plan.Alternate.FuelRequired = "2600"
+	margin, err = plan.AlternateFuelMargin()
+	require.NoError(t, err)
+	assert.Equal(t, -200.0, margin)
+
+	_, err = (&types.FlightPlanResponse{}).AlternateFuelMargin()
+	assert.ErrorIs(t, err, types.ErrNoAlternate)
+
+	// Hand-built responses may only set Alternates
+	margin, err = (&types.FlightPlanResponse{
+		Fuel:       types.FuelInfo{Alternate: "2400"},
+		Alternates: []types.AlternateInfo{{ICAO: "EGKK", FuelRequired: "2000"}, {ICAO: "EGSS", FuelRequired: "3000"}},
+	}).AlternateFuelMargin()
+	require.NoError(t, err)
+	assert.Equal(t, 400.0, margin)
+}
+
+func TestCustomAircraftApplied(t *testing.T) {
+	sent := &types.AircraftData{OEW: 99.5, MTOW: 194.7}
+
+	plan := &types.FlightPlanResponse{
+		Params:   types.FlightParams{Units: "lbs"},
+		Aircraft: types.AircraftInfo{OEW: 99500, MTOW: 194700},
+	}
+	assert.True(t, plan.CustomAircraftApplied(sent))
+
+	kgsPlan := &types.FlightPlanResponse{
+		Params:   types.FlightParams{Units: "kgs"},
+		Aircraft: types.AircraftInfo{OEW: 45133, MTOW: 88315},
+	}
+	assert.True(t, kgsPlan.CustomAircraftApplied(sent))
+
+	// Default B38M weights echoed instead of the custom ones
+	ignored := &types.FlightPlanResponse{
+		Params:   types.FlightParams{Units: "lbs"},
+		Aircraft: types.AircraftInfo{OEW: 99360, MTOW: 182200},
+	}
+	assert.False(t, ignored.CustomAircraftApplied(sent))
+
+	assert.True(t, ignored.CustomAircraftApplied(nil))
+}
+
+func TestChatSummary(t *testing.T) {
+	plan := &types.FlightPlanResponse{
+		Origin:      types.AirportInfo{ICAO: "KJFK"},
+		Destination: types.AirportInfo{ICAO: "EGLL"},
+		Aircraft:    types.AircraftInfo{ICAO: "B39M"},
+		General:     types.GeneralInfo{Distance: "3004", Units: "kgs"},
+		Times:       types.TimeInfo{BlockTime: "24720"},
+		Fuel:        types.FuelInfo{Plan: "45000"},
+	}
+	assert.Equal(t, "KJFK → EGLL | B39M | 3004 nm | block 6h52m | fuel 45000 kgs", plan.ChatSummary())
+
+	plan.Times.BlockTime = "21600"
+	assert.Contains(t, plan.ChatSummary(), "| block 6h00m |")
+	plan.Times.BlockTime = "2700"
+	assert.Contains(t, plan.ChatSummary(), "| block 0h45m |")
+	plan.Times.BlockTime = "20"
+	assert.NotContains(t, plan.ChatSummary(), "block")
+	plan.Times.BlockTime = "24720"
+
+	plan.General.Units = "lbs"
+	assert.Contains(t, plan.ChatSummary(), "fuel 45000 lbs")
+
+	plan.Aircraft.ICAO = strings.Repeat("X", 300)
+	assert.LessOrEqual(t, len([]rune(plan.ChatSummary())), types.ChatSummaryMaxLength)
+
+	sparse := &types.FlightPlanResponse{
+		Origin:      types.AirportInfo{ICAO: "KJFK"},
+		Destination: types.AirportInfo{ICAO: "KBOS"},
+	}
+	assert.Equal(t, "KJFK → KBOS", sparse.ChatSummary())
+}
+
+func TestFlightPlanBuilderBuildValidated(t *testing.T) {
+	request, err := NewFlightPlan("KJFK", "KLAX", "B738").BuildValidated()
+	require.NoError(t, err)
+	assert.Equal(t, "KJFK", request.Origin)
+
+	request, err = NewFlightPlan("KJFK", "", "B738").BuildValidated()
+	assert.Nil(t, request)
+	assert.ErrorIs(t, err, types.ErrMissingDestination)
+
+	_, err = NewFlightPlan("KJFK", "", "B738").FlightNumber("TOOLONG1").BuildValidated()
+	assert.ErrorIs(t, err, types.ErrMissingDestination)
+	assert.ErrorIs(t, err, types.ErrInvalidFlightNumber)
+}
+
+func TestDecodeTankeringFuel(t *testing.T) {
+	var plan types.FlightPlanResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"fuel": {"plan_ramp": "45000", "tankering": "3500"}}`), &plan))
+	assert.Equal(t, "3500", plan.Fuel.Tankering)
+
+	var fromXML types.FlightPlanResponse
+	require.NoError(t, xml.Unmarshal([]byte(`<OFP><fuel><tankering>1200</tankering></fuel></OFP>`), &fromXML))
+	assert.Equal(t, "1200", fromXML.Fuel.Tankering)
+
+	var none types.FlightPlanResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"fuel": {"plan_ramp": "45000"}}`), &none))
+	assert.Empty(t, none.Fuel.Tankering)
+}
+
+func TestPreflightCheck(t *testing.T) {
+	client := NewClient()
+
+	assert.Empty(t, client.PreflightCheck(NewFlightPlan("KJFK", "KLAX", "B738").Route("HAPIE J174 COATE").Build()))
+
+	req := NewFlightPlan("KJFK", "KLAX", "").
+		Route(strings.Repeat("HAPIE J174 ", 200)).
+		Build()
+
+	issues := client.PreflightCheck(req)
+	require.Len(t, issues, 2)
+	assert.Contains(t, issues[0], "aircraft type")
+	assert.Contains(t, issues[1], "route is")
+
+	// Every missing required field is reported
+	issues = client.PreflightCheck(&types.FlightPlanRequest{Destination: "KLAX"})
+	require.Len(t, issues, 2)
+	assert.Equal(t, types.ErrMissingOrigin.Error(), issues[0])
+	assert.Equal(t, types.ErrMissingAircraft.Error(), issues[1])
+
+	req = NewFlightPlan("KJFK", "KLAX", "B738").Build()
+	req.Extra = map[string]string{"bogus_param": "1", "taxifuel": "600"}
+	issues = client.PreflightCheck(req)
+	require.Len(t, issues, 1, "accepted but unmodeled parameters are not issues")
+	assert.Equal(t, `unrecognized parameter "bogus_param"`, issues[0])
+	assert.True(t, types.IsSimBriefParam("taxifuel"))
+	assert.True(t, types.IsSimBriefParam("orig"))
+	assert.False(t, types.IsSimBriefParam("bogus_param"))
+
+	assert.True(t, types.IsKnownFormKey("orig"))
+	assert.False(t, types.IsKnownFormKey("origin"))
+}
+
+func TestClientTransportTimeouts(t *testing.T) {
+	client := NewClient()
+	client.SetUserAgent("test-agent")
+
+	require.NoError(t, client.SetDialTimeout(2*time.Second))
+	require.NoError(t, client.SetTLSHandshakeTimeout(3*time.Second))
+	require.NoError(t, client.SetResponseHeaderTimeout(4*time.Second))
+
+	ua, ok := client.HTTPClient.Transport.(*userAgentTransport)
+	require.True(t, ok, "User-Agent wrapper must be preserved")
+	transport, ok := ua.Transport.(*http.Transport)
+	require.True(t, ok)
+	assert.NotSame(t, http.DefaultTransport, transport)
+	assert.Equal(t, 3*time.Second, transport.TLSHandshakeTimeout)
+	assert.Equal(t, 4*time.Second, transport.ResponseHeaderTimeout)
+	assert.NotNil(t, transport.DialContext)
+	assert.Equal(t, DefaultTimeout, client.HTTPClient.Timeout)
+
+	assert.NotEqual(t, 4*time.Second, http.DefaultTransport.(*http.Transport).ResponseHeaderTimeout)
+
+	custom := NewClientWithConfig("", &http.Client{Transport: roundTripperFunc(http.DefaultTransport.RoundTrip)})
+	assert.Error(t, custom.SetDialTimeout(time.Second))
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestETOPS(t *testing.T) {
+	var plan types.FlightPlanResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"general": {"is_etops": "1", "etops_rule": "180"}}`), &plan))
+
+	rule, ok := plan.ETOPS()
+	assert.True(t, ok)
+	assert.Equal(t, 180, rule)
+
+	require.NoError(t, xml.Unmarshal([]byte(`<OFP><general><is_etops>0</is_etops></general></OFP>`), &plan))
+	_, ok = plan.ETOPS()
+	assert.False(t, ok)
+}
+
+func TestCruiseSpeed(t *testing.T) {
+	var plan types.FlightPlanResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"general": {"cruise_tas": "452", "cruise_mach": ".78"}}`), &plan))
+
+	tas, mach, err := plan.CruiseSpeed()
+	require.NoError(t, err)
+	assert.Equal(t, 452.0, tas)
+	assert.InDelta(t, 0.78, mach, 0.0001)
+
+	tas, mach, err = (&types.FlightPlanResponse{}).CruiseSpeed()
+	require.NoError(t, err)
+	assert.Zero(t, tas)
+	assert.Zero(t, mach)
+
+	plan.General.CruiseMach = "M78"
+	_, _, err = plan.CruiseSpeed()
+	assert.Error(t, err)
+}
+
+func TestSupportedOptionsTop(t *testing.T) {
+	options := &types.SupportedOptions{
+		Aircraft: map[string]types.AircraftOption{
+			"A320": {ID: "A320", PopularityPct: 18.5},
+			"B738": {ID: "B738", PopularityPct: 21.2},
+			"A20N": {ID: "A20N", PopularityPct: 9.1},
+			"C172": {ID: "C172", PopularityPct: 9.1},
+		},
+		Layouts: map[string]types.LayoutOption{
+			"LIDO": {ID: "LIDO", PopularityPct: 55},
+			"UAL":  {ID: "UAL", PopularityPct: 12},
+		},
+	}
+
+	top := options.TopAircraft(3)
+	require.Len(t, top, 3)
+	assert.Equal(t, "B738", top[0].ID)
+	assert.Equal(t, "A320", top[1].ID)
+	assert.Equal(t, "A20N", top[2].ID)
+
+	assert.Len(t, options.TopAircraft(10), 4)
+	assert.Empty(t, options.TopAircraft(0))
+
+	layouts := options.TopLayouts(1)
+	require.Len(t, layouts, 1)
+	assert.Equal(t, "LIDO", layouts[0].ID)
+}
+
+func TestDecodeSELCALField(t *testing.T) {
+	var withCode types.FlightPlanResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"aircraft": {"icaocode": "B738", "selcal": "ab-cd"}}`), &withCode))
+	assert.Equal(t, "ABCD", withCode.Aircraft.SELCAL.String())
+
+	var empty types.FlightPlanResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"aircraft": {"icaocode": "B738", "selcal": {}}}`), &empty))
+	assert.Empty(t, empty.Aircraft.SELCAL.String())
+
+	var fromXML types.FlightPlanResponse
+	require.NoError(t, xml.Unmarshal([]byte(`<OFP><aircraft><selcal>EF-GH</selcal></aircraft></OFP>`), &fromXML))
+	assert.Equal(t, "EFGH", fromXML.Aircraft.SELCAL.String())
+
+	data, err := json.Marshal(withCode.Aircraft.SELCAL)
+	require.NoError(t, err)
+	assert.JSONEq(t, `"ABCD"`, string(data))
+}
+
+func TestNavLogFixWindComponents(t *testing.T) {
+	tests := []struct {
+		name      string
+		fix       types.NavLogFix
+		wantHead  float64
+		wantCross float64
+		wantErr   bool
+	}{
+		{name: "direct headwind", fix: types.NavLogFix{Track: 270, Wind: "270/45"}, wantHead: 45},
+		{name: "direct tailwind", fix: types.NavLogFix{Track: 90, Wind: "270/45"}, wantHead: -45},
+		{name: "crosswind from right", fix: types.NavLogFix{Track: 360, Wind: "090/20"}, wantCross: 20},
+		{name: "crosswind from left", fix: types.NavLogFix{Track: 360, Wind: "27020"}, wantCross: -20},
+		{name: "calm", fix: types.NavLogFix{Track: 123, Wind: "00000"}},
+		{name: "invalid", fix: types.NavLogFix{Track: 90, Wind: "VRB"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			head, cross, err := tt.fix.WindComponents()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, tt.wantHead, head, 0.001)
+			assert.InDelta(t, tt.wantCross, cross, 0.001)
+		})
+	}
+}
+
+func TestSumLegTimes(t *testing.T) {
+	var plan types.FlightPlanResponse
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"times": {"est_time_enroute": "2700"},
+		"navlog": {"fix": [
+			{"ident": "KJFK", "time_leg": ""},
+			{"ident": "HAPIE", "time_leg": "600"},
+			{"ident": "ALLRY", "time_leg": "1500"},
+			{"ident": "KBOS", "time_leg": "600"}
+		]}
+	}`), &plan))
+
+	total, err := plan.SumLegTimes()
+	require.NoError(t, err)
+	assert.Equal(t, 45*time.Minute, total)
+
+	plan.NavLog.Fixes[2].ETE = "abc"
+	_, err = plan.SumLegTimes()
+	assert.Error(t, err)
+}
+
+func TestWithInsecureSkipVerify(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "mock-tests", r.Header.Get("User-Agent"))
+		fmt.Fprint(w, `{"params": {"request_id": "42"}}`)
+	}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0) // expected handshake failure below
+	server.StartTLS()
+	defer server.Close()
+
+	// Verification stays on by default
+	secure := NewClientWithConfig(server.URL, nil)
+	_, err := secure.GetFlightPlanByUserID("123456")
+	assert.Error(t, err)
+
+	client := NewClientWithConfig(server.URL, nil, WithInsecureSkipVerify())
+	client.SetUserAgent("mock-tests")
+
+	ua, ok := client.HTTPClient.Transport.(*userAgentTransport)
+	require.True(t, ok)
+	transport, ok := ua.Transport.(*http.Transport)
+	require.True(t, ok)
+	require.NotNil(t, transport.TLSClientConfig)
+	assert.True(t, transport.TLSClientConfig.InsecureSkipVerify)
+	if cfg := http.DefaultTransport.(*http.Transport).TLSClientConfig; cfg != nil {
+		assert.False(t, cfg.InsecureSkipVerify, "default transport must not be modified")
+	}
+
+	plan, err := client.GetFlightPlanByUserID("123456")
+	require.NoError(t, err)
+	assert.Equal(t, "42", plan.Params.RequestID)
+}
+
+func TestMarshalSummaryJSON(t *testing.T) {
+	plan := &types.FlightPlanResponse{
+		Origin:      types.AirportInfo{ICAO: "KJFK"},
+		Destination: types.AirportInfo{ICAO: "EGLL"},
+		Aircraft:    types.AircraftInfo{ICAO: "B39M"},
+		General:     types.GeneralInfo{Route: "HAPIE N247A ALLRY", Units: "kgs"},
+		Times:       types.TimeInfo{BlockTime: "24720"},
+		Fuel:        types.FuelInfo{Plan: "45,000"},
+		Weights:     types.WeightInfo{PaxCount: "180"},
+	}
+
+	data, err := plan.MarshalSummaryJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"origin": "KJFK",
+		"destination": "EGLL",
+		"aircraft": "B39M",
+		"route": "HAPIE N247A ALLRY",
+		"block_time_min": 412,
+		"ramp_fuel": 45000,
+		"units": "kgs",
+		"pax": 180
+	}`, string(data))
+}
+
+func TestValidateDate(t *testing.T) {
+	assert.NoError(t, types.ValidateDate("15Jul23"))
+	assert.NoError(t, types.ValidateDate("11JUL13"))
+	assert.NoError(t, types.ValidateDate("29Feb24"))
+	assert.Error(t, types.ValidateDate("29Feb23"))
+	assert.Error(t, types.ValidateDate("2024-07-15"))
+	assert.Error(t, types.ValidateDate("5Jul24"))
+
+	date, err := types.DateFromISO("2024-07-15")
+	require.NoError(t, err)
+	assert.Equal(t, "15Jul24", date)
+	_, err = types.DateFromISO("2024-13-01")
+	assert.Error(t, err)
+}
+
+func TestGenerateFlightPlanURLWithKey(t *testing.T) {
+	client := NewClient()
+	client.APIKey = "client-key"
+	req := NewFlightPlan("KJFK", "KLAX", "B738").Build()
+
+	generated := client.GenerateFlightPlanURLWithKey(req, "tenant-key")
+	parsed, err := url.Parse(generated)
+	require.NoError(t, err)
+	assert.Equal(t, "tenant-key", parsed.Query().Get("api_key"))
+	assert.Equal(t, "KJFK", parsed.Query().Get("orig"))
+	assert.Equal(t, "client-key", client.APIKey, "client key must not change")
+
+	redacted := RedactURL(generated)
+	assert.NotContains(t, redacted, "tenant-key")
+	assert.Contains(t, redacted, "api_key=REDACTED")
+
+	assert.NotContains(t, client.GenerateFlightPlanURL(req), "api_key")
+}
+
+func TestGenerateFlightPlanURLs(t *testing.T) {
+	client := NewClient()
+	reqs := []*types.FlightPlanRequest{
+		NewFlightPlan("KJFK", "KLAX", "B738").StaticID("FLT_1").Build(),
+		NewFlightPlan("", "KLAX", "B738").StaticID("FLT_2").Build(),
+		NewFlightPlan("EGLL", "LFPG", "A320").Build(),
+		NewFlightPlan("EDDF", "LEMD", "A321").StaticID("FLT_1").Build(),
+	}
+
+	urls, err := client.GenerateFlightPlanURLs(reqs)
+	assert.ErrorIs(t, err, types.ErrMissingOrigin)
+	require.Len(t, urls, 4)
+	assert.Equal(t, client.GenerateFlightPlanURL(reqs[0]), urls[0])
+	assert.Empty(t, urls[1])
+	assert.NotEmpty(t, urls[2])
+
+	byID, err := client.GenerateFlightPlanURLsByStaticID(reqs)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, types.ErrMissingOrigin)
+	assert.Contains(t, err.Error(), "static ID is required")
+	assert.Contains(t, err.Error(), `duplicate static ID "FLT_1"`)
+	assert.Equal(t, map[string]string{"FLT_1": urls[0]}, byID)
+}
+
+func TestGenerateFlightPlanURLsForFormats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"aircraft": {}, "layouts": {"LIDO": {"id": "LIDO"}, "UAL": {"id": "UAL"}}}`)
+	}))
+	defer server.Close()
+
+	req := NewFlightPlan("KJFK", "KLAX", "B738").Build()
+	formats := []types.PlanFormat{types.PlanFormatLIDO, types.PlanFormatUAL, types.PlanFormatBAW}
+
+	uncached := NewClientWithConfig(server.URL, nil)
+	urls := uncached.GenerateFlightPlanURLsForFormats(req, formats)
+	require.Len(t, urls, 3)
+	assert.Contains(t, urls[types.PlanFormatUAL], "planformat=UAL")
+	assert.Empty(t, req.PlanFormat, "request must not be modified")
+
+	cached := NewClientWithConfig(server.URL, nil, WithSharedOptionsCache())
+	urls = cached.GenerateFlightPlanURLsForFormats(req, formats)
+	assert.Len(t, urls, 2)
+	assert.NotContains(t, urls, types.PlanFormatBAW)
+}
+
+func TestCheckFeatureSupport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"aircraft": {
+			"B738": {"id": "B738", "chart_data": true, "costindex_data": true, "tlr_data": true},
+			"C172": {"id": "C172", "chart_data": false, "costindex_data": false, "tlr_data": false}
+		}}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithConfig(server.URL, nil)
+	tlr := true
+	build := func(aircraft string) *types.FlightPlanRequest {
+		req := NewFlightPlan("KJFK", "KBOS", aircraft).Build()
+		req.CostIndex = "30"
+		req.RunwayAnalysis = &tlr
+		req.Maps = "detail"
+		return req
+	}
+
+	assert.Empty(t, client.CheckFeatureSupport(build("B738")))
+
+	warnings := client.CheckFeatureSupport(build("c172"))
+	require.Len(t, warnings, 3)
+	assert.Contains(t, warnings[0], "TLR")
+	assert.Contains(t, warnings[1], "cost index")
+	assert.Contains(t, warnings[2], "chart data")
+
+	assert.Len(t, client.CheckFeatureSupport(build("ZZZZ")), 1)
+}
+
+func TestElevationFeet(t *testing.T) {
+	for elevation, want := range map[string]int{"13 ft": 13, "13": 13, "-1266 ft": -1266, "5,431 FT": 5431, "42feet": 42} {
+		got, err := types.AirportInfo{Elevation: elevation}.ElevationFeet()
+		require.NoError(t, err, elevation)
+		assert.Equal(t, want, got, elevation)
+	}
+
+	for _, elevation := range []string{"", "ft", "13 m"} {
+		_, err := types.AirportInfo{Elevation: elevation}.ElevationFeet()
+		assert.Error(t, err, elevation)
+	}
+}
+
+func TestFlightPlanRequestExtra(t *testing.T) {
+	req := NewFlightPlan("KJFK", "KLAX", "B738").Build()
+	req.Extra = map[string]string{"extrarmk": "1", "orig": "EGLL"}
+
+	values := req.ToURLValues()
+	assert.Equal(t, "1", values.Get("extrarmk"))
+	assert.Equal(t, "KJFK", values.Get("orig"), "extra must not override a modeled parameter")
+
+	err := req.Validate()
+	assert.ErrorIs(t, err, types.ErrExtraParamConflict)
+	assert.Contains(t, err.Error(), "orig")
+
+	delete(req.Extra, "orig")
+	assert.NoError(t, req.Validate())
+	assert.Empty(t, NewClient().PreflightCheck(req))
+
+	clone := req.Clone()
+	clone.Extra["extrarmk"] = "0"
+	assert.Equal(t, "1", req.Extra["extrarmk"], "Clone must copy Extra")
+}
+
+func TestFuelIn(t *testing.T) {
+	plan := &types.FlightPlanResponse{
+		General: types.GeneralInfo{Units: "lbs"},
+		Fuel:    types.FuelInfo{Plan: "10,000", Trip: "8000", Taxi: "400", Tankering: ""},
+	}
+
+	kgs, err := plan.FuelIn(types.UnitsKGS)
+	require.NoError(t, err)
+	assert.Equal(t, types.UnitsKGS, kgs.Units)
+	assert.InDelta(t, 4535.92, kgs.Ramp, 0.001)
+	assert.InDelta(t, 3628.736, kgs.Trip, 0.001)
+	assert.Zero(t, kgs.Tankering)
+
+	lbs, err := plan.FuelIn("lbs")
+	require.NoError(t, err)
+	assert.Equal(t, 10000.0, lbs.Ramp)
+
+	_, err = plan.FuelIn("tonnes")
+	assert.Error(t, err)
+
+	plan.Fuel.Reserve = "n/a"
+	_, err = plan.FuelIn(types.UnitsKGS)
+	assert.ErrorContains(t, err, "reserve")
+
+	_, err = (&types.FlightPlanResponse{}).FuelIn(types.UnitsKGS)
+	assert.Error(t, err)
+}
+
+func TestWeightInfoNumeric(t *testing.T) {
+	weights := types.WeightInfo{PaxCount: "180", PaxWeight: "34,200", BagWeight: "", Cargo: "12500", ZFW: "138000"}
+
+	n, err := weights.Numeric()
+	require.NoError(t, err)
+	assert.Equal(t, 180, n.PaxCount)
+	assert.Equal(t, 34200.0, n.PaxWeight)
+	assert.Zero(t, n.BagWeight)
+	assert.Equal(t, 12500.0, n.Cargo)
+	assert.Equal(t, 138000.0, n.ZFW)
+
+	pax, err := weights.PaxCountInt()
+	require.NoError(t, err)
+	assert.Equal(t, 180, pax)
+
+	bags, err := weights.BagWeightFloat()
+	require.NoError(t, err)
+	assert.Zero(t, bags)
+
+	cargo, err := weights.CargoFloat()
+	require.NoError(t, err)
+	assert.Equal(t, 12500.0, cargo)
+
+	_, err = types.WeightInfo{PaxCount: "12.5"}.PaxCountInt()
+	assert.Error(t, err)
+
+	_, err = types.WeightInfo{Cargo: "lots"}.Numeric()
+	assert.ErrorContains(t, err, "cargo")
+}
+
+func TestVerifyUserID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("userid") {
+		case "111111":
+			w.Write([]byte(`{"params": {"user_id": "111111"}}`))
+		case "222222":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"fetch": {"status": "Error: No flight plan on file for the specified user"}}`))
+		case "333333":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"fetch": {"status": "Error: Unknown UserID"}}`))
+		case "555555":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"fetch": {"status": "Error: Unknown user agent"}}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithConfig(server.URL, nil)
+	ctx := context.Background()
+
+	for userID, want := range map[string]bool{"111111": true, "222222": true, "333333": false} {
+		exists, err := client.VerifyUserID(ctx, userID)
+		require.NoError(t, err, userID)
+		assert.Equal(t, want, exists, userID)
+	}
+
+	exists, err := client.VerifyUserID(ctx, "444444")
+	assert.False(t, exists)
+	var httpErr *types.HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, http.StatusInternalServerError, httpErr.StatusCode)
+
+	// Statuses other than the documented ones are errors, even when they look similar
+	exists, err = client.VerifyUserID(ctx, "555555")
+	assert.False(t, exists)
+	var apiErr types.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "Error: Unknown user agent", apiErr.Message)
+	assert.Equal(t, http.StatusBadRequest, apiErr.Code)
+}
+
+func TestCanonicalKey(t *testing.T) {
+	a := NewFlightPlan("KJFK", "KLAX", "B738").Route("DCT  ROBUC3  ").Passengers(150).Build()
+	b := &types.FlightPlanRequest{Passengers: 150, Route: "dct robuc3", Aircraft: "b738", Destination: "klax", Origin: " KJFK"}
+	assert.Equal(t, a.CanonicalKey(), b.CanonicalKey())
+	assert.Len(t, a.CanonicalKey(), 64)
+
+	b.Passengers = 151
+	assert.NotEqual(t, a.CanonicalKey(), b.CanonicalKey())
+}
+
+func TestFlightPlanResponseValidate(t *testing.T) {
+	plan := &types.FlightPlanResponse{
+		General: types.GeneralInfo{Route: "HAPIE J174 COATE"},
+		Fuel:    types.FuelInfo{Plan: "24500"},
+		Weights: types.WeightInfo{TakeoffWt: "160000"},
+	}
+	assert.Empty(t, plan.Validate())
+
+	plan.Fuel.Plan = ""
+	plan.General.Route = " "
+	warnings := plan.Validate()
+	require.Len(t, warnings, 2)
+	assert.Contains(t, warnings[0], "plan_ramp")
+	assert.Contains(t, warnings[1], "route")
+}
+
+func TestGeneralInfoNavIDs(t *testing.T) {
+	general := types.GeneralInfo{RouteNAVID: " HAPIE  COATE\tJFK "}
+	assert.Equal(t, []string{"HAPIE", "COATE", "JFK"}, general.NavIDs())
+
+	empty := types.GeneralInfo{}.NavIDs()
+	assert.NotNil(t, empty)
+	assert.Empty(t, empty)
+}
+
+func TestGeneralInfoStepClimbSegments(t *testing.T) {
+	general := types.GeneralInfo{StepClimbs: "FL340/N0450 BETTE/0360 N0455F380 CYMON/37000/M078"}
+	steps, err := general.StepClimbSegments()
+	require.NoError(t, err)
+	assert.Equal(t, []types.StepClimb{
+		{Altitude: 34000, Speed: "N0450"},
+		{Position: "BETTE", Altitude: 36000},
+		{Altitude: 38000, Speed: "N0455"},
+		{Position: "CYMON", Altitude: 37000, Speed: "M078"},
+	}, steps)
+
+	steps, err = types.GeneralInfo{}.StepClimbSegments()
+	require.NoError(t, err)
+	assert.NotNil(t, steps)
+	assert.Empty(t, steps)
+
+	_, err = types.GeneralInfo{StepClimbs: "BETTE/N0450"}.StepClimbSegments()
+	assert.ErrorContains(t, err, "no altitude")
+	_, err = types.GeneralInfo{StepClimbs: "FL340/BETTE/RESNO"}.StepClimbSegments()
+	assert.Error(t, err)
+}
+
+func TestGetOptionsSorted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"aircraft": {"B738": {"id": "B738"}, "A320": {"id": "A320"}, "C172": {"id": "C172"}},
+			"layouts": {"UAL": {"id": "UAL"}, "LIDO": {"id": "LIDO"}}
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithConfig(server.URL, nil)
+
+	aircraft, err := client.GetAircraftTypesSorted()
+	require.NoError(t, err)
+	require.Len(t, aircraft, 3)
+	assert.Equal(t, []string{"A320", "B738", "C172"}, []string{aircraft[0].ID, aircraft[1].ID, aircraft[2].ID})
+
+	layouts, err := client.GetPlanFormatsSorted()
+	require.NoError(t, err)
+	require.Len(t, layouts, 2)
+	assert.Equal(t, "LIDO", layouts[0].ID)
+	assert.Equal(t, "UAL", layouts[1].ID)
+}
+
+func TestGetFlightPlanMerged(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Query().Get("json") == "1" {
+			w.Write([]byte(`{"origin": {"icao_code": "KJFK", "name": "Kennedy"}, "general": {"route": "HAPIE J174"}}`))
+			return
+		}
+		w.Write([]byte(`<OFP>
+			<origin><icao_code>XXXX</icao_code><elevation>13</elevation></origin>
+			<destination><icao_code>EGLL</icao_code></destination>
+			<general><route>IGNORED</route><cruise_tas>452</cruise_tas></general>
+			<navlog><fix><ident>HAPIE</ident></fix></navlog>
+		</OFP>`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithConfig(server.URL, nil)
+	plan, err := client.GetFlightPlanMerged("123456")
+	require.NoError(t, err)
+	assert.Equal(t, 2, requests)
+
+	assert.Equal(t, "KJFK", plan.Origin.ICAO, "JSON values win")
+	assert.Equal(t, "Kennedy", plan.Origin.Name)
+	assert.Equal(t, "13", plan.Origin.Elevation, "gaps are filled from XML")
+	assert.Equal(t, "EGLL", plan.Destination.ICAO)
+	assert.Equal(t, "HAPIE J174", plan.General.Route)
+	assert.Equal(t, "452", plan.General.CruiseTAS)
+	require.Len(t, plan.NavLogFixes(), 1)
+	assert.Equal(t, "HAPIE", plan.NavLogFixes()[0].Ident)
+}
+
+func TestRouteEfficiency(t *testing.T) {
+	kjfk := types.LatLon{Lat: 40.639801, Lon: -73.7789}
+	egll := types.LatLon{Lat: 51.4706, Lon: -0.461941}
+	assert.InDelta(t, 2990, types.GreatCircleDistanceNM(kjfk, egll), 5)
+
+	plan := &types.FlightPlanResponse{
+		Origin:      types.AirportInfo{ICAO: "KJFK", Latitude: "40.639801", Longitude: "-73.778900"},
+		Destination: types.AirportInfo{ICAO: "EGLL", Latitude: "51.470600", Longitude: "-0.461941"},
+		General:     types.GeneralInfo{Distance: "3150"},
+	}
+	efficiency, err := plan.RouteEfficiency()
+	require.NoError(t, err)
+	assert.InDelta(t, 94.9, efficiency, 0.2)
+
+	plan.General.Distance = "0"
+	_, err = plan.RouteEfficiency()
+	assert.Error(t, err)
+
+	plan.General.Distance = "3150"
+	plan.Origin.Latitude = ""
+	_, err = plan.RouteEfficiency()
+	assert.ErrorContains(t, err, "KJFK")
+}
+
+func TestStrictDecoding(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/inputs.list.json" {
+			w.Write([]byte(`{"aircraft": {}, "layouts": {}, "new_section": {}}`))
+			return
+		}
+		w.Write([]byte(`{"origin": {"icao_code": "KJFK", "new_field": "x"}}`))
+	}))
+	defer server.Close()
+
+	lenient := NewClientWithConfig(server.URL, nil)
+	plan, err := lenient.GetFlightPlanByUserID("123456")
+	require.NoError(t, err)
+	assert.Equal(t, "KJFK", plan.Origin.ICAO)
+	_, err = lenient.GetSupportedOptions()
+	require.NoError(t, err)
+
+	strict := NewClientWithConfig(server.URL, nil, WithStrictDecoding())
+	_, err = strict.GetFlightPlanByUserID("123456")
+	assert.ErrorContains(t, err, `unknown field "new_field"`)
+	_, err = strict.GetSupportedOptions()
+	assert.ErrorContains(t, err, `unknown field "new_section"`)
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	"github.com/mrlm-net/simbrief/pkg/types"
+)
+
+// feetToMeters converts altitudes from feet to meters
+const feetToMeters = 0.3048
+
+// gpxDocument is the root element of a GPX 1.1 document
+type gpxDocument struct {
+	XMLName  xml.Name `xml:"gpx"`
+	Version  string   `xml:"version,attr"`
+	Creator  string   `xml:"creator,attr"`
+	XMLNS    string   `xml:"xmlns,attr"`
+	Metadata gpxMeta  `xml:"metadata"`
+	Route    gpxRoute `xml:"rte"`
+	Track    gpxTrack `xml:"trk"`
+}
+
+type gpxMeta struct {
+	Name string `xml:"name"`
+}
+
+type gpxRoute struct {
+	Name   string     `xml:"name"`
+	Points []gpxPoint `xml:"rtept"`
+}
+
+type gpxTrack struct {
+	Name    string          `xml:"name"`
+	Segment gpxTrackSegment `xml:"trkseg"`
+}
+
+type gpxTrackSegment struct {
+	Points []gpxPoint `xml:"trkpt"`
+}
+
+type gpxPoint struct {
+	Lat       float64  `xml:"lat,attr"`
+	Lon       float64  `xml:"lon,attr"`
+	Elevation *float64 `xml:"ele,omitempty"`
+	Name      string   `xml:"name,omitempty"`
+	Type      string   `xml:"type,omitempty"`
+}
+
+// NavLogToGPX exports the navigation log as a GPX 1.1 document containing a route of
+// waypoints and a track line. Elevation is taken from the planned altitude when available.
+func NavLogToGPX(plan *types.FlightPlanResponse) ([]byte, error) {
+	if plan == nil {
+		return nil, fmt.Errorf("flight plan is required")
+	}
+	fixes := plan.NavLogFixes()
+	if len(fixes) == 0 {
+		return nil, fmt.Errorf("flight plan has no navlog fixes")
+	}
+
+	name := plan.Origin.ICAO + "-" + plan.Destination.ICAO
+	doc := gpxDocument{
+		Version:  "1.1",
+		Creator:  "github.com/mrlm-net/simbrief",
+		XMLNS:    "http://www.topografix.com/GPX/1/1",
+		Metadata: gpxMeta{Name: name},
+		Route:    gpxRoute{Name: name},
+		Track:    gpxTrack{Name: name},
+	}
+
+	for _, fix := range fixes {
+		point := gpxPoint{Lat: fix.Latitude, Lon: fix.Longitude}
+		if fix.Altitude > 0 {
+			elevation := float64(fix.Altitude) * feetToMeters
+			point.Elevation = &elevation
+		}
+		doc.Track.Segment.Points = append(doc.Track.Segment.Points, point)
+
+		point.Name = fix.Ident
+		point.Type = fix.Type
+		doc.Route.Points = append(doc.Route.Points, point)
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode GPX: %w", err)
+	}
+	return append([]byte(xml.Header), data...), nil
+}
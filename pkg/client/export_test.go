@@ -0,0 +1,109 @@
+package client
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/mrlm-net/simbrief/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testNavLogPlan() *types.FlightPlanResponse {
+	return &types.FlightPlanResponse{
+		Origin:      types.AirportInfo{ICAO: "KJFK"},
+		Destination: types.AirportInfo{ICAO: "KLAX"},
+		NavLog: &types.NavLog{
+			Fixes: []types.NavLogFix{
+				{Ident: "KJFK", Type: "apt", Latitude: 40.6398, Longitude: -73.7789},
+				{Ident: "HAPIE", Type: "wpt", Latitude: 40.5, Longitude: -74.5, Altitude: 18000},
+				{Ident: "COATE", Type: "vor", Latitude: 41.2, Longitude: -76.8, Altitude: 35000},
+				{Ident: "KLAX", Type: "apt", Latitude: 33.9425, Longitude: -118.4081},
+			},
+		},
+	}
+}
+
+func TestNavLogToGPX(t *testing.T) {
+	data, err := NavLogToGPX(testNavLogPlan())
+	require.NoError(t, err)
+
+	var doc struct {
+		XMLName xml.Name `xml:"gpx"`
+		Version string   `xml:"version,attr"`
+		Route   []struct {
+			Name string   `xml:"name"`
+			Ele  *float64 `xml:"ele"`
+		} `xml:"rte>rtept"`
+		Track []struct {
+			Lat float64 `xml:"lat,attr"`
+		} `xml:"trk>trkseg>trkpt"`
+	}
+	require.NoError(t, xml.Unmarshal(data, &doc))
+
+	assert.Equal(t, "1.1", doc.Version)
+	require.Len(t, doc.Route, 4)
+	assert.Equal(t, "KJFK", doc.Route[0].Name)
+	assert.Equal(t, "KLAX", doc.Route[3].Name)
+	assert.Nil(t, doc.Route[0].Ele)
+	require.NotNil(t, doc.Route[2].Ele)
+	assert.InDelta(t, 10668.0, *doc.Route[2].Ele, 0.01)
+	assert.Len(t, doc.Track, 4)
+
+	_, err = NavLogToGPX(&types.FlightPlanResponse{})
+	assert.Error(t, err)
+}
+
+func TestEncodePolyline(t *testing.T) {
+	// Reference example from the encoded polyline algorithm documentation
+	plan := &types.FlightPlanResponse{
+		NavLog: &types.NavLog{
+			Fixes: []types.NavLogFix{
+				{Latitude: 38.5, Longitude: -120.2},
+				{Latitude: 40.7, Longitude: -120.95},
+				{Latitude: 43.252, Longitude: -126.453},
+			},
+		},
+	}
+	encoded, err := plan.EncodePolyline()
+	require.NoError(t, err)
+	assert.Equal(t, "_p~iF~ps|U_ulLnnqC_mqNvxq`@", encoded)
+
+	_, err = (&types.FlightPlanResponse{}).EncodePolyline()
+	assert.Error(t, err)
+}
+
+func TestExportGeoJSON(t *testing.T) {
+	data, err := testNavLogPlan().ExportGeoJSON()
+	require.NoError(t, err)
+
+	var collection struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Geometry struct {
+				Type        string          `json:"type"`
+				Coordinates json.RawMessage `json:"coordinates"`
+			} `json:"geometry"`
+			Properties map[string]interface{} `json:"properties"`
+		} `json:"features"`
+	}
+	require.NoError(t, json.Unmarshal(data, &collection))
+
+	assert.Equal(t, "FeatureCollection", collection.Type)
+	require.Len(t, collection.Features, 5)
+	assert.Equal(t, "LineString", collection.Features[0].Geometry.Type)
+	assert.JSONEq(t, `[[-73.7789,40.6398],[-74.5,40.5],[-76.8,41.2],[-118.4081,33.9425]]`,
+		string(collection.Features[0].Geometry.Coordinates))
+
+	coate := collection.Features[3]
+	assert.Equal(t, "Point", coate.Geometry.Type)
+	assert.JSONEq(t, `[-76.8,41.2]`, string(coate.Geometry.Coordinates))
+	assert.Equal(t, "COATE", coate.Properties["ident"])
+	assert.Equal(t, "vor", coate.Properties["type"])
+	assert.Equal(t, 35000.0, coate.Properties["altitude"])
+
+	data, err = (&types.FlightPlanResponse{}).ExportGeoJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type": "FeatureCollection", "features": []}`, string(data))
+}
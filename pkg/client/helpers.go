@@ -1,7 +1,9 @@
 package client
 
 import (
+	"errors"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -9,9 +11,13 @@ import (
 	"github.com/mrlm-net/simbrief/pkg/types"
 )
 
+// maxCruiseAltitudeFeet is the highest cruise altitude accepted by the builder
+const maxCruiseAltitudeFeet = 60000
+
 // FlightPlanBuilder provides a fluent interface for building flight plan requests
 type FlightPlanBuilder struct {
 	request *types.FlightPlanRequest
+	errs    []error // invalid inputs recorded by setters, reported by BuildValidated
 }
 
 // NewFlightPlan creates a new flight plan builder with required fields
@@ -34,11 +40,22 @@ func (b *FlightPlanBuilder) Airline(airline string) *FlightPlanBuilder {
 }
 
 // FlightNumber sets the flight number
+// An invalid flight number is not set and is recorded as an error, see Errors
 func (b *FlightPlanBuilder) FlightNumber(flightNumber string) *FlightPlanBuilder {
+	if err := types.ValidateFlightNumber(flightNumber); err != nil {
+		b.errs = append(b.errs, err)
+		return b
+	}
 	b.request.FlightNumber = flightNumber
 	return b
 }
 
+// NormalizedFlightNumber sets the flight number like FlightNumber after applying
+// types.NormalizeFlightNumber, e.g. "0918" with minDigits 0 becomes "918"
+func (b *FlightPlanBuilder) NormalizedFlightNumber(flightNumber string, minDigits int) *FlightPlanBuilder {
+	return b.FlightNumber(types.NormalizeFlightNumber(flightNumber, minDigits))
+}
+
 // Alternate sets the alternate airport
 func (b *FlightPlanBuilder) Alternate(alternate string) *FlightPlanBuilder {
 	b.request.Alternate = alternate
@@ -46,9 +63,69 @@ func (b *FlightPlanBuilder) Alternate(alternate string) *FlightPlanBuilder {
 }
 
 // DepartureTime sets the departure time
+// An hour outside 0-23 or minute outside 0-59 is recorded as an error, see Errors
 func (b *FlightPlanBuilder) DepartureTime(hour, minute int) *FlightPlanBuilder {
-	b.request.DepartureHour = hour
-	b.request.DepartureMinute = minute
+	if hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		b.errs = append(b.errs, fmt.Errorf("invalid departure time %02d:%02d", hour, minute))
+		return b
+	}
+	b.request.DepartureHour = &hour
+	b.request.DepartureMinute = &minute
+	return b
+}
+
+// ScheduledEnrouteTime sets the scheduled time enroute (steh/stem) from a duration
+// The duration must be positive and under 24 hours; seconds are truncated
+func (b *FlightPlanBuilder) ScheduledEnrouteTime(d time.Duration) *FlightPlanBuilder {
+	if d <= 0 || d >= 24*time.Hour {
+		b.errs = append(b.errs, fmt.Errorf("scheduled enroute time must be between 0 and 24 hours, got %s", d))
+		return b
+	}
+	hours := int(d / time.Hour)
+	minutes := int((d % time.Hour) / time.Minute)
+	b.request.ScheduledHour = &hours
+	b.request.ScheduledMinute = &minutes
+	return b
+}
+
+// Contingency sets the contingency fuel as a fraction of trip fuel (0.05 = 5%) with an
+// optional minimum in minutes; minMinutes <= 0 sends the fraction only
+func (b *FlightPlanBuilder) Contingency(pct float64, minMinutes int) *FlightPlanBuilder {
+	cf := types.ContingencyFuel{Fraction: pct, MinMinutes: minMinutes}
+	if err := cf.Validate(); err != nil {
+		b.errs = append(b.errs, err)
+		return b
+	}
+	b.request.ContFuelPct = cf.String()
+	return b
+}
+
+// ExtraFuelWeight sets extra fuel as a weight in the request units (addedfuel in "wgt" mode).
+// It is mutually exclusive with ExtraFuelMinutes; a negative weight or a conflicting mode
+// is recorded as an error, see Errors
+func (b *FlightPlanBuilder) ExtraFuelWeight(weight float64) *FlightPlanBuilder {
+	return b.extraFuel(strconv.FormatFloat(weight, 'f', -1, 64), weight < 0, types.FuelUnitsWeight)
+}
+
+// ExtraFuelMinutes sets extra fuel as minutes of flight (addedfuel in "min" mode).
+// It is mutually exclusive with ExtraFuelWeight; negative minutes or a conflicting mode
+// is recorded as an error, see Errors
+func (b *FlightPlanBuilder) ExtraFuelMinutes(minutes int) *FlightPlanBuilder {
+	return b.extraFuel(strconv.Itoa(minutes), minutes < 0, types.FuelUnitsTime)
+}
+
+// extraFuel sets addedfuel and its units unless the value is negative or the other mode is set
+func (b *FlightPlanBuilder) extraFuel(value string, negative bool, units types.FuelUnits) *FlightPlanBuilder {
+	if negative {
+		b.errs = append(b.errs, fmt.Errorf("extra fuel cannot be negative, got %s %s", value, units))
+		return b
+	}
+	if current := b.request.AddedFuelUnits; current != "" && current != string(units) {
+		b.errs = append(b.errs, fmt.Errorf("extra fuel is already set in %q mode, cannot also set %q", current, units))
+		return b
+	}
+	b.request.AddedFuel = value
+	b.request.AddedFuelUnits = string(units)
 	return b
 }
 
@@ -60,7 +137,19 @@ func (b *FlightPlanBuilder) Date(date string) *FlightPlanBuilder {
 
 // DateFromTime sets the departure date from a time.Time
 func (b *FlightPlanBuilder) DateFromTime(t time.Time) *FlightPlanBuilder {
-	b.request.Date = t.Format("02Jan06")
+	b.request.Date = t.Format(types.DateLayout)
+	return b
+}
+
+// DateFromISO sets the departure date from an ISO 8601 date ("2024-07-15")
+// An invalid date is recorded as an error, see Errors
+func (b *FlightPlanBuilder) DateFromISO(iso string) *FlightPlanBuilder {
+	date, err := types.DateFromISO(iso)
+	if err != nil {
+		b.errs = append(b.errs, err)
+		return b
+	}
+	b.request.Date = date
 	return b
 }
 
@@ -71,29 +160,66 @@ func (b *FlightPlanBuilder) Altitude(altitude string) *FlightPlanBuilder {
 }
 
 // AltitudeFromFeet sets the cruise altitude from feet
+// Values outside 1-60000 ft are recorded as an error, see Errors
 func (b *FlightPlanBuilder) AltitudeFromFeet(feet int) *FlightPlanBuilder {
+	if feet <= 0 || feet > maxCruiseAltitudeFeet {
+		b.errs = append(b.errs, fmt.Errorf("cruise altitude must be between 1 and %d ft, got %d", maxCruiseAltitudeFeet, feet))
+		return b
+	}
 	b.request.Altitude = fmt.Sprintf("%d", feet)
 	return b
 }
 
 // AltitudeFromFlightLevel sets the cruise altitude from flight level
+// Levels outside FL001-FL600 are recorded as an error, see Errors
 func (b *FlightPlanBuilder) AltitudeFromFlightLevel(fl int) *FlightPlanBuilder {
+	if fl <= 0 || fl*100 > maxCruiseAltitudeFeet {
+		b.errs = append(b.errs, fmt.Errorf("flight level must be between 1 and %d, got %d", maxCruiseAltitudeFeet/100, fl))
+		return b
+	}
 	b.request.Altitude = fmt.Sprintf("FL%03d", fl)
 	return b
 }
 
 // Passengers sets the number of passengers
 func (b *FlightPlanBuilder) Passengers(pax int) *FlightPlanBuilder {
+	if pax < 0 {
+		b.errs = append(b.errs, fmt.Errorf("passenger count cannot be negative, got %d", pax))
+		return b
+	}
 	b.request.Passengers = pax
 	return b
 }
 
 // Cargo sets the cargo weight
 func (b *FlightPlanBuilder) Cargo(cargo float64) *FlightPlanBuilder {
+	if cargo < 0 {
+		b.errs = append(b.errs, fmt.Errorf("cargo cannot be negative, got %g", cargo))
+		return b
+	}
 	b.request.Cargo = cargo
 	return b
 }
 
+// CargoKG sets the cargo from a weight in kilograms, converted to the request units
+// Call Units first; requests without units are treated as LBS. The value uses the same
+// scale as Cargo (e.g. 5.0 for 5000 kg).
+func (b *FlightPlanBuilder) CargoKG(kg float64) *FlightPlanBuilder {
+	if b.request.Units != types.UnitsKGS {
+		kg = NewFuelHelper().ConvertKGSToLBS(kg)
+	}
+	return b.Cargo(kg)
+}
+
+// CargoLBS sets the cargo from a weight in pounds, converted to the request units
+// Call Units first; requests without units are treated as LBS.
+func (b *FlightPlanBuilder) CargoLBS(lbs float64) *FlightPlanBuilder {
+	if b.request.Units == types.UnitsKGS {
+		lbs = NewFuelHelper().ConvertLBSToKGS(lbs)
+	}
+	return b.Cargo(lbs)
+}
+
 // Units sets the weight/fuel units
 func (b *FlightPlanBuilder) Units(units types.Units) *FlightPlanBuilder {
 	b.request.Units = units
@@ -112,30 +238,92 @@ func (b *FlightPlanBuilder) Registration(reg string) *FlightPlanBuilder {
 	return b
 }
 
+// Fin sets the aircraft fin number
+func (b *FlightPlanBuilder) Fin(fin string) *FlightPlanBuilder {
+	b.request.FinNumber = fin
+	return b
+}
+
+// PilotID sets the pilot ID number
+func (b *FlightPlanBuilder) PilotID(id string) *FlightPlanBuilder {
+	b.request.PilotID = id
+	return b
+}
+
 // CallSign sets the ATC callsign
 func (b *FlightPlanBuilder) CallSign(callsign string) *FlightPlanBuilder {
 	b.request.ATCCallsign = callsign
 	return b
 }
 
+// SELCAL sets the aircraft SELCAL code
+// A code that is not four valid SELCAL letters is recorded as an error, see Errors
+func (b *FlightPlanBuilder) SELCAL(selcal string) *FlightPlanBuilder {
+	if err := types.ValidateSELCAL(selcal); err != nil {
+		b.errs = append(b.errs, err)
+		return b
+	}
+	b.request.SELCAL = selcal
+	return b
+}
+
 // Captain sets the captain's name
 func (b *FlightPlanBuilder) Captain(name string) *FlightPlanBuilder {
 	b.request.CaptainName = name
 	return b
 }
 
+// Username sets the SimBrief username the generated plan is associated with
+func (b *FlightPlanBuilder) Username(username string) *FlightPlanBuilder {
+	b.request.Username = username
+	return b
+}
+
 // Dispatcher sets the dispatcher's name
 func (b *FlightPlanBuilder) Dispatcher(name string) *FlightPlanBuilder {
 	b.request.DispatcherName = name
 	return b
 }
 
+// Remarks sets the manual remarks from individual lines, joined with newlines
+// Empty or whitespace-only lines are dropped
+func (b *FlightPlanBuilder) Remarks(lines ...string) *FlightPlanBuilder {
+	kept := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			kept = append(kept, line)
+		}
+	}
+	b.request.ManualRemarks = strings.Join(kept, "\n")
+	return b
+}
+
+// AddRemark appends a single line to the manual remarks
+func (b *FlightPlanBuilder) AddRemark(line string) *FlightPlanBuilder {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return b
+	}
+	if b.request.ManualRemarks != "" {
+		b.request.ManualRemarks += "\n"
+	}
+	b.request.ManualRemarks += line
+	return b
+}
+
 // StaticID sets a static reference ID
 func (b *FlightPlanBuilder) StaticID(id string) *FlightPlanBuilder {
 	b.request.StaticID = id
 	return b
 }
 
+// StaticIDSafe sets a static reference ID cleaned with types.SanitizeStaticID, which
+// uppercases it, replaces unsafe characters with '_' and truncates it to types.MaxStaticIDLength
+func (b *FlightPlanBuilder) StaticIDSafe(id string) *FlightPlanBuilder {
+	b.request.StaticID = types.SanitizeStaticID(id)
+	return b
+}
+
 // EnableNavLog enables detailed navigation log
 func (b *FlightPlanBuilder) EnableNavLog() *FlightPlanBuilder {
 	enable := true
@@ -170,10 +358,28 @@ func (b *FlightPlanBuilder) CustomAircraftData(data *types.AircraftData) *Flight
 	return b
 }
 
+// Extra sets a generation parameter the SDK does not model yet, sent verbatim.
+// A key that is already modeled by FlightPlanRequest is recorded as an error, see Errors
+func (b *FlightPlanBuilder) Extra(key, value string) *FlightPlanBuilder {
+	if types.IsKnownFormKey(key) {
+		b.errs = append(b.errs, fmt.Errorf("%w: %s", types.ErrExtraParamConflict, key))
+		return b
+	}
+	if b.request.Extra == nil {
+		b.request.Extra = make(map[string]string)
+	}
+	b.request.Extra[key] = value
+	return b
+}
+
 // TaxiTimes sets taxi out and taxi in times in minutes
 func (b *FlightPlanBuilder) TaxiTimes(taxiOut, taxiIn int) *FlightPlanBuilder {
-	b.request.TaxiOut = taxiOut
-	b.request.TaxiIn = taxiIn
+	if taxiOut < 0 || taxiIn < 0 {
+		b.errs = append(b.errs, fmt.Errorf("taxi times cannot be negative, got %d/%d", taxiOut, taxiIn))
+		return b
+	}
+	b.request.TaxiOut = &taxiOut
+	b.request.TaxiIn = &taxiIn
 	return b
 }
 
@@ -184,11 +390,214 @@ func (b *FlightPlanBuilder) Runways(departure, arrival string) *FlightPlanBuilde
 	return b
 }
 
+// ValidatedRunways sets departure and arrival runways like Runways, but checks each
+// non-empty runway with types.ValidateRunway. Invalid runways are recorded as errors, see Errors
+func (b *FlightPlanBuilder) ValidatedRunways(departure, arrival string) *FlightPlanBuilder {
+	if departure != "" {
+		if err := types.ValidateRunway(departure); err != nil {
+			b.errs = append(b.errs, err)
+		} else {
+			b.request.OriginRunway = departure
+		}
+	}
+	if arrival != "" {
+		if err := types.ValidateRunway(arrival); err != nil {
+			b.errs = append(b.errs, err)
+		} else {
+			b.request.DestRunway = arrival
+		}
+	}
+	return b
+}
+
 // Build returns the completed flight plan request
 func (b *FlightPlanBuilder) Build() *types.FlightPlanRequest {
 	return b.request
 }
 
+// BuildValidated returns the completed flight plan request, or an error joining the invalid
+// inputs recorded by setters and the required-field check of the request
+func (b *FlightPlanBuilder) BuildValidated() (*types.FlightPlanRequest, error) {
+	errs := append([]error(nil), b.errs...)
+	if err := b.request.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return b.request, nil
+}
+
+// Normalize cleans the identifier fields in one pass: registration, callsign and SELCAL
+// are uppercased with invalid characters stripped, airport and airline codes are trimmed
+// and uppercased. Call it after the setters and before Build.
+func (b *FlightPlanBuilder) Normalize() *FlightPlanBuilder {
+	r := b.request
+
+	r.Registration = types.NormalizeRegistration(r.Registration)
+	r.ATCCallsign = types.NormalizeCallsign(r.ATCCallsign)
+	r.SELCAL = types.NormalizeSELCAL(r.SELCAL)
+
+	for _, code := range []*string{
+		&r.Origin, &r.Destination, &r.Aircraft, &r.Airline, &r.Alternate,
+		&r.Altn1ID, &r.Altn2ID, &r.Altn3ID, &r.Altn4ID,
+		&r.OriginRunway, &r.DestRunway, &r.FlightNumber,
+	} {
+		*code = strings.ToUpper(strings.TrimSpace(*code))
+	}
+
+	return b
+}
+
+// Clone returns an independent copy of the builder, deep-copying the request
+// so the clone can be changed without affecting the original template
+func (b *FlightPlanBuilder) Clone() *FlightPlanBuilder {
+	return &FlightPlanBuilder{
+		request: b.request.Clone(),
+		errs:    append([]error(nil), b.errs...),
+	}
+}
+
+// Errors returns the invalid inputs recorded by setters so far
+func (b *FlightPlanBuilder) Errors() []error {
+	return b.errs
+}
+
+// AircraftDataBuilder provides a fluent interface for building custom aircraft data
+type AircraftDataBuilder struct {
+	data *types.AircraftData
+}
+
+// NewAircraftData creates a new aircraft data builder
+func NewAircraftData() *AircraftDataBuilder {
+	return &AircraftDataBuilder{
+		data: &types.AircraftData{},
+	}
+}
+
+// Identity sets the ICAO identifier, display name and engine type
+func (b *AircraftDataBuilder) Identity(icao, name, engines string) *AircraftDataBuilder {
+	b.data.ICAO = icao
+	b.data.Name = name
+	b.data.Engines = engines
+	return b
+}
+
+// ICAOFlightPlan sets the weight category, equipment and transponder fields, which must be specified together
+func (b *AircraftDataBuilder) ICAOFlightPlan(cat, equip, transponder string) *AircraftDataBuilder {
+	b.data.Category = cat
+	b.data.Equipment = equip
+	b.data.Transponder = transponder
+	return b
+}
+
+// PBN sets the performance based navigation string (must start with "PBN/")
+func (b *AircraftDataBuilder) PBN(pbn string) *AircraftDataBuilder {
+	b.data.PBN = pbn
+	return b
+}
+
+// ExtraRemark sets additional Section 18 information
+func (b *AircraftDataBuilder) ExtraRemark(remark string) *AircraftDataBuilder {
+	b.data.ExtraRemark = remark
+	return b
+}
+
+// Weights sets the aircraft weights in thousands of pounds
+func (b *AircraftDataBuilder) Weights(oew, mzfw, mtow, mlw, maxfuel float64) *AircraftDataBuilder {
+	b.data.OEW = oew
+	b.data.MZFW = mzfw
+	b.data.MTOW = mtow
+	b.data.MLW = mlw
+	b.data.MaxFuel = maxfuel
+	return b
+}
+
+// Passengers sets the maximum passenger count and average passenger weight in pounds
+func (b *AircraftDataBuilder) Passengers(maxPax, paxWeight int) *AircraftDataBuilder {
+	b.data.MaxPax = strconv.Itoa(maxPax)
+	b.data.PaxWgt = paxWeight
+	return b
+}
+
+// HexCode sets the ICAO Mode-S code
+func (b *AircraftDataBuilder) HexCode(hex string) *AircraftDataBuilder {
+	b.data.HexCode = hex
+	return b
+}
+
+// PerformanceCategory sets the ICAO performance category
+func (b *AircraftDataBuilder) PerformanceCategory(per types.PerformanceCategory) *AircraftDataBuilder {
+	b.data.Per = string(per)
+	return b
+}
+
+// Build validates the cross-field rules and returns the completed aircraft data
+func (b *AircraftDataBuilder) Build() (*types.AircraftData, error) {
+	d := b.data
+
+	// ICAO flight plan fields must be all set or all empty
+	icaoSet := 0
+	for _, v := range []string{d.Category, d.Equipment, d.Transponder} {
+		if v != "" {
+			icaoSet++
+		}
+	}
+	if icaoSet != 0 && icaoSet != 3 {
+		return nil, fmt.Errorf("category, equipment and transponder must be specified together")
+	}
+	if d.Category != "" {
+		switch types.AircraftCategory(d.Category) {
+		case types.AircraftCategoryLight, types.AircraftCategoryMedium,
+			types.AircraftCategoryHeavy, types.AircraftCategorySuper:
+		default:
+			return nil, fmt.Errorf("invalid aircraft category: %s", d.Category)
+		}
+	}
+	if d.Equipment != "" {
+		if err := types.ValidateEquipment(d.Equipment); err != nil {
+			return nil, err
+		}
+	}
+	if d.Transponder != "" {
+		if err := types.ValidateTransponder(d.Transponder); err != nil {
+			return nil, err
+		}
+	}
+
+	if d.PBN != "" && !strings.HasPrefix(d.PBN, "PBN/") {
+		return nil, fmt.Errorf("PBN must start with \"PBN/\"")
+	}
+
+	if len(d.ICAO) > 4 {
+		return nil, fmt.Errorf("ICAO aircraft identifier must be at most 4 characters")
+	}
+	if len(d.Name) > 12 {
+		return nil, fmt.Errorf("aircraft name must be at most 12 characters")
+	}
+	if len(d.Engines) > 12 {
+		return nil, fmt.Errorf("engine type must be at most 12 characters")
+	}
+
+	// Weight ordering checks, only applied when both sides are set
+	for _, w := range []float64{d.OEW, d.MZFW, d.MTOW, d.MLW, d.MaxFuel} {
+		if w < 0 {
+			return nil, fmt.Errorf("weights cannot be negative")
+		}
+	}
+	if d.OEW > 0 && d.MZFW > 0 && d.OEW >= d.MZFW {
+		return nil, fmt.Errorf("OEW (%.3f) must be less than MZFW (%.3f)", d.OEW, d.MZFW)
+	}
+	if d.MZFW > 0 && d.MTOW > 0 && d.MZFW > d.MTOW {
+		return nil, fmt.Errorf("MZFW (%.3f) cannot exceed MTOW (%.3f)", d.MZFW, d.MTOW)
+	}
+	if d.MLW > 0 && d.MTOW > 0 && d.MLW > d.MTOW {
+		return nil, fmt.Errorf("MLW (%.3f) cannot exceed MTOW (%.3f)", d.MLW, d.MTOW)
+	}
+
+	return d, nil
+}
+
 // RouteHelper provides utilities for working with flight routes
 type RouteHelper struct{}
 
@@ -218,6 +627,40 @@ func (rh *RouteHelper) ParseRoute(route string) []string {
 	return waypoints
 }
 
+// procedurePattern matches SID/STAR names such as HAPIE6, ANJLL4 or BOGNA1A:
+// three to six letters followed by a single digit and an optional letter
+var procedurePattern = regexp.MustCompile(`^[A-Z]{3,6}[0-9][A-Z]?$`)
+
+// SplitRoute splits a route into SID, enroute and STAR parts using heuristics:
+//   - leading and trailing ICAO airport codes (4 characters) are dropped
+//   - the first token is the SID if it looks like a procedure (see procedurePattern)
+//   - the last token is the STAR if it looks like a procedure
+//   - everything in between is enroute
+//
+// Airways such as J174 or UL607 never match the procedure pattern. Routes without
+// procedures are returned entirely as enroute.
+func (rh *RouteHelper) SplitRoute(route string) (sid, enroute, star string) {
+	tokens := rh.ParseRoute(strings.ToUpper(route))
+
+	if len(tokens) > 0 && rh.ValidateICAOCode(tokens[0]) {
+		tokens = tokens[1:]
+	}
+	if len(tokens) > 0 && rh.ValidateICAOCode(tokens[len(tokens)-1]) {
+		tokens = tokens[:len(tokens)-1]
+	}
+
+	if len(tokens) > 0 && procedurePattern.MatchString(tokens[0]) {
+		sid = tokens[0]
+		tokens = tokens[1:]
+	}
+	if len(tokens) > 0 && procedurePattern.MatchString(tokens[len(tokens)-1]) {
+		star = tokens[len(tokens)-1]
+		tokens = tokens[:len(tokens)-1]
+	}
+
+	return sid, strings.Join(tokens, " "), star
+}
+
 // ValidateICAOCode validates an ICAO airport code format
 func (rh *RouteHelper) ValidateICAOCode(code string) bool {
 	if len(code) != 4 {
@@ -281,6 +724,7 @@ func (fh *FuelHelper) ConvertKGSToLBS(kgs float64) float64 {
 }
 
 // ParseFuelValue parses a fuel value string that might contain weight or time
+// Numbers are parsed with types.ParseNumber, so thousands separators are accepted
 func (fh *FuelHelper) ParseFuelValue(value string) (float64, string, error) {
 	value = strings.TrimSpace(value)
 	if value == "" {
@@ -291,7 +735,7 @@ func (fh *FuelHelper) ParseFuelValue(value string) (float64, string, error) {
 	if strings.Contains(value, "/") {
 		parts := strings.Split(value, "/")
 		if len(parts) == 2 {
-			weight, err := strconv.ParseFloat(parts[0], 64)
+			weight, err := types.ParseNumber(parts[0])
 			if err != nil {
 				return 0, "", fmt.Errorf("invalid weight component: %s", parts[0])
 			}
@@ -300,7 +744,7 @@ func (fh *FuelHelper) ParseFuelValue(value string) (float64, string, error) {
 	}
 
 	// Try to parse as a simple float
-	weight, err := strconv.ParseFloat(value, 64)
+	weight, err := types.ParseNumber(value)
 	if err != nil {
 		return 0, "", fmt.Errorf("invalid fuel value: %s", value)
 	}
@@ -3,6 +3,8 @@ package client
 import (
 	"testing"
 	"time"
+
+	"github.com/mrlm-net/simbrief/pkg/types"
 )
 
 func TestRouteHelper_ParseRoute(t *testing.T) {
@@ -77,6 +79,58 @@ func TestRouteHelper_ValidateICAOCode(t *testing.T) {
 	}
 }
 
+func TestRouteHelper_SplitRoute(t *testing.T) {
+	helper := NewRouteHelper()
+
+	tests := []struct {
+		name        string
+		route       string
+		wantSID     string
+		wantEnroute string
+		wantSTAR    string
+	}{
+		{
+			name:        "SID and STAR",
+			route:       "HAPIE6 HAPIE J174 COATE ANJLL4",
+			wantSID:     "HAPIE6",
+			wantEnroute: "HAPIE J174 COATE",
+			wantSTAR:    "ANJLL4",
+		},
+		{
+			name:        "with airports",
+			route:       "KJFK DEEZZ5 CANDR J60 PSB ANJLL4 KLAX",
+			wantSID:     "DEEZZ5",
+			wantEnroute: "CANDR J60 PSB",
+			wantSTAR:    "ANJLL4",
+		},
+		{
+			name:        "no procedures",
+			route:       "HAPIE N247A ALLRY UL607 REDFA",
+			wantEnroute: "HAPIE N247A ALLRY UL607 REDFA",
+		},
+		{
+			name:        "SID only",
+			route:       "bogna1a BOGNA DCT REDFA",
+			wantSID:     "BOGNA1A",
+			wantEnroute: "BOGNA DCT REDFA",
+		},
+		{
+			name:  "empty route",
+			route: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sid, enroute, star := helper.SplitRoute(tt.route)
+			if sid != tt.wantSID || enroute != tt.wantEnroute || star != tt.wantSTAR {
+				t.Errorf("SplitRoute(%q) = (%q, %q, %q), want (%q, %q, %q)",
+					tt.route, sid, enroute, star, tt.wantSID, tt.wantEnroute, tt.wantSTAR)
+			}
+		})
+	}
+}
+
 func TestRouteHelper_FormatFlightLevel(t *testing.T) {
 	helper := NewRouteHelper()
 
@@ -162,6 +216,9 @@ func TestFuelHelper_ParseFuelValue(t *testing.T) {
 		{name: "simple weight", value: "5000", wantWeight: 5000, wantTime: "", wantErr: false},
 		{name: "weight and time", value: "0.05/15", wantWeight: 0.05, wantTime: "15", wantErr: false},
 		{name: "with spaces", value: " 5000 ", wantWeight: 5000, wantTime: "", wantErr: false},
+		{name: "thousands separator", value: "10,000", wantWeight: 10000, wantTime: "", wantErr: false},
+		{name: "thousands separator and time", value: "10,000/15", wantWeight: 10000, wantTime: "15", wantErr: false},
+		{name: "decimal comma", value: "10,5", wantWeight: 0, wantTime: "", wantErr: true},
 		{name: "empty", value: "", wantWeight: 0, wantTime: "", wantErr: true},
 		{name: "invalid", value: "ABC", wantWeight: 0, wantTime: "", wantErr: true},
 	}
@@ -328,3 +385,172 @@ func TestFlightPlanBuilder_AltitudeFromFlightLevel(t *testing.T) {
 		t.Errorf("AltitudeFromFlightLevel(340) = %s, want %s", request.Altitude, expected)
 	}
 }
+
+func TestFlightPlanBuilder_AccumulatesErrors(t *testing.T) {
+	builder := NewFlightPlan("KJFK", "KLAX", "B738").
+		AltitudeFromFlightLevel(900).
+		Passengers(-1).
+		SELCAL("AB-IO").
+		DepartureTime(14, 30)
+
+	if got := len(builder.Errors()); got != 3 {
+		t.Fatalf("Errors() length = %d, want 3: %v", got, builder.Errors())
+	}
+
+	request := builder.Build()
+	if request.Altitude != "" {
+		t.Errorf("invalid flight level was applied: %q", request.Altitude)
+	}
+	if request.DepartureHour == nil || *request.DepartureHour != 14 {
+		t.Errorf("valid departure time was not applied")
+	}
+
+	if _, err := builder.BuildValidated(); err == nil {
+		t.Errorf("BuildValidated() expected an error for invalid flight level")
+	}
+
+	if _, err := NewFlightPlan("KJFK", "KLAX", "B738").AltitudeFromFlightLevel(350).SELCAL("ab-cd").BuildValidated(); err != nil {
+		t.Errorf("BuildValidated() unexpected error: %v", err)
+	}
+}
+
+func TestFlightPlanBuilder_DateFromISO(t *testing.T) {
+	request := NewFlightPlan("KJFK", "KLAX", "B738").DateFromISO("2024-07-15").Build()
+	if request.Date != "15Jul24" {
+		t.Errorf("DateFromISO() = %s, want 15Jul24", request.Date)
+	}
+
+	builder := NewFlightPlan("KJFK", "KLAX", "B738").DateFromISO("2023-02-30")
+	if len(builder.Errors()) != 1 {
+		t.Errorf("DateFromISO() with impossible date should record an error")
+	}
+	if builder.Build().Date != "" {
+		t.Errorf("DateFromISO() with impossible date should not set the date")
+	}
+}
+
+func TestFlightPlanBuilder_CargoUnits(t *testing.T) {
+	tests := []struct {
+		name  string
+		units types.Units
+		kg    float64
+		lbs   float64
+		want  float64
+	}{
+		{name: "kg into KGS request", units: types.UnitsKGS, kg: 5, want: 5},
+		{name: "kg into LBS request", units: types.UnitsLBS, kg: 5, want: 11.023},
+		{name: "kg without units defaults to LBS", kg: 5, want: 11.023},
+		{name: "lbs into KGS request", units: types.UnitsKGS, lbs: 10, want: 4.536},
+		{name: "lbs into LBS request", units: types.UnitsLBS, lbs: 10, want: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := NewFlightPlan("KJFK", "KLAX", "B738")
+			if tt.units != "" {
+				builder.Units(tt.units)
+			}
+			if tt.kg != 0 {
+				builder.CargoKG(tt.kg)
+			} else {
+				builder.CargoLBS(tt.lbs)
+			}
+
+			got := builder.Build().Cargo
+			if got < tt.want-0.001 || got > tt.want+0.001 {
+				t.Errorf("Cargo = %f, want ~%f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlightPlanBuilder_ValidatedRunways(t *testing.T) {
+	tests := []struct {
+		runway  string
+		wantErr bool
+	}{
+		{"06L", false},
+		{"36", false},
+		{"01C", false},
+		{"18R", false},
+		{"6L", true},
+		{"37C", true},
+		{"00", true},
+		{"27X", true},
+		{"RW27", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.runway, func(t *testing.T) {
+			err := types.ValidateRunway(tt.runway)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRunway(%q) error = %v, wantErr %v", tt.runway, err, tt.wantErr)
+			}
+		})
+	}
+
+	builder := NewFlightPlan("KJFK", "KLAX", "B738").ValidatedRunways("31L", "6L")
+	request := builder.Build()
+	if request.OriginRunway != "31L" || request.DestRunway != "" {
+		t.Errorf("ValidatedRunways() set %q/%q, want 31L and no arrival runway", request.OriginRunway, request.DestRunway)
+	}
+	if len(builder.Errors()) != 1 {
+		t.Errorf("ValidatedRunways() should record one error, got %v", builder.Errors())
+	}
+}
+
+func TestFlightPlanBuilder_Extra(t *testing.T) {
+	builder := NewFlightPlan("KJFK", "KLAX", "B738").Extra("new_option", "1").Extra("dest", "KSFO")
+	request := builder.Build()
+
+	if request.Extra["new_option"] != "1" {
+		t.Errorf("Extra() did not set new_option, got %v", request.Extra)
+	}
+	if _, ok := request.Extra["dest"]; ok || request.Destination != "KLAX" {
+		t.Errorf("Extra() with a modeled key should be rejected, got %v", request.Extra)
+	}
+	if len(builder.Errors()) != 1 {
+		t.Errorf("Extra() with a modeled key should record one error, got %v", builder.Errors())
+	}
+}
+
+func TestFlightPlanBuilder_StaticIDSafe(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"kjfk/egll 1", "KJFK_EGLL_1"},
+		{"  ual918-a ", "UAL918-A"},
+		{"café", "CAF_"},
+		{"ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789", "ABCDEFGHIJKLMNOPQRSTUVWXYZ012345"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			request := NewFlightPlan("KJFK", "KLAX", "B738").StaticIDSafe(tt.input).Build()
+			if request.StaticID != tt.want {
+				t.Errorf("StaticIDSafe(%q) = %q, want %q", tt.input, request.StaticID, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlightPlanBuilder_ExtraFuel(t *testing.T) {
+	request := NewFlightPlan("KJFK", "KLAX", "B738").ExtraFuelWeight(1500.5).Build()
+	if request.AddedFuel != "1500.5" || request.AddedFuelUnits != "wgt" {
+		t.Errorf("ExtraFuelWeight() = %s %s, want 1500.5 wgt", request.AddedFuel, request.AddedFuelUnits)
+	}
+
+	request = NewFlightPlan("KJFK", "KLAX", "B738").ExtraFuelMinutes(20).Build()
+	if request.AddedFuel != "20" || request.AddedFuelUnits != "min" {
+		t.Errorf("ExtraFuelMinutes() = %s %s, want 20 min", request.AddedFuel, request.AddedFuelUnits)
+	}
+
+	builder := NewFlightPlan("KJFK", "KLAX", "B738").ExtraFuelMinutes(20).ExtraFuelWeight(1000).ExtraFuelMinutes(-5)
+	if got := len(builder.Errors()); got != 2 {
+		t.Errorf("Errors() length = %d, want 2: %v", got, builder.Errors())
+	}
+	if request := builder.Build(); request.AddedFuel != "20" || request.AddedFuelUnits != "min" {
+		t.Errorf("conflicting extra fuel changed the request to %s %s", request.AddedFuel, request.AddedFuelUnits)
+	}
+}
@@ -0,0 +1,398 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DecisionFuel computes the break-even fuel for the destination/alternate decision.
+//
+// Model assumptions:
+//   - the decision is taken overhead the destination (after trip fuel has been burnt)
+//   - contingency and extra fuel are considered usable for holding, so they are not part of the minimum
+//   - a diversion must still land at the alternate with the final reserve intact
+//
+// The result is therefore alternate burn + reserve: with more fuel than this on board at the
+// destination the crew can continue to hold or approach, with less it must divert.
+// The value is in the plan's units (see General.Units).
+func DecisionFuel(fp *FlightPlanResponse) (float64, error) {
+	if fp == nil {
+		return 0, fmt.Errorf("flight plan is required")
+	}
+	if strings.TrimSpace(fp.Fuel.Alternate) == "" {
+		return 0, ErrNoAlternate
+	}
+
+	alternate, err := ParseNumber(fp.Fuel.Alternate)
+	if err != nil {
+		return 0, fmt.Errorf("invalid alternate fuel: %w", err)
+	}
+	reserve, err := ParseNumber(fp.Fuel.Reserve)
+	if err != nil {
+		return 0, fmt.Errorf("invalid reserve fuel: %w", err)
+	}
+
+	return alternate + reserve, nil
+}
+
+// AlternateETEFromDest estimates the time to divert from overhead the destination to the
+// alternate, using the planned alternate distance (nm) and the given ground speed (knots)
+func AlternateETEFromDest(fp *FlightPlanResponse, groundSpeedKts float64) (time.Duration, error) {
+	if fp == nil {
+		return 0, fmt.Errorf("flight plan is required")
+	}
+	if groundSpeedKts <= 0 {
+		return 0, fmt.Errorf("ground speed must be positive")
+	}
+	alternate := fp.primaryAlternate()
+	if strings.TrimSpace(alternate.Distance) == "" {
+		return 0, ErrNoAlternate
+	}
+
+	distance, err := ParseNumber(alternate.Distance)
+	if err != nil {
+		return 0, fmt.Errorf("invalid alternate distance: %w", err)
+	}
+	if distance < 0 {
+		return 0, fmt.Errorf("alternate distance cannot be negative")
+	}
+
+	hours := distance / groundSpeedKts
+	return time.Duration(hours * float64(time.Hour)).Round(time.Second), nil
+}
+
+// AlternateFuelMargin returns the planned alternate fuel (Fuel.Alternate) minus the burn
+// required to reach the alternate (Alternate.FuelRequired). A negative margin means the plan
+// carries less alternate fuel than required. Returns ErrNoAlternate when none is planned.
+func (fp *FlightPlanResponse) AlternateFuelMargin() (float64, error) {
+	alternate := fp.primaryAlternate()
+	if strings.TrimSpace(alternate.ICAO) == "" && strings.TrimSpace(alternate.FuelRequired) == "" {
+		return 0, ErrNoAlternate
+	}
+
+	planned, err := ParseNumber(fp.Fuel.Alternate)
+	if err != nil {
+		return 0, fmt.Errorf("invalid alternate fuel: %w", err)
+	}
+	required, err := ParseNumber(alternate.FuelRequired)
+	if err != nil {
+		return 0, fmt.Errorf("invalid alternate burn: %w", err)
+	}
+
+	return planned - required, nil
+}
+
+// customAircraftTolerance is the relative difference allowed between sent and echoed
+// weights, covering SimBrief's rounding and unit conversion
+const customAircraftTolerance = 0.005
+
+// CustomAircraftApplied reports whether the response aircraft echoes the weights of the
+// custom AircraftData that was sent (OEW, MZFW, MTOW, MLW and max fuel, when set).
+// SimBrief silently ignores malformed acdata, in which case the defaults are returned.
+// Sent weights (thousands of pounds) are converted to the plan units, assuming pounds when
+// the plan units are unknown. Nothing to compare (nil or empty data) counts as applied.
+func (fp *FlightPlanResponse) CustomAircraftApplied(sent *AircraftData) bool {
+	if sent.IsEmpty() {
+		return true
+	}
+
+	units, err := fp.PlanUnits()
+	if err != nil {
+		units = UnitsLBS
+	}
+
+	pairs := []struct{ sent, echoed float64 }{
+		{sent.OEW, fp.Aircraft.OEW},
+		{sent.MZFW, fp.Aircraft.MZFW},
+		{sent.MTOW, fp.Aircraft.MTOW},
+		{sent.MLW, fp.Aircraft.MLW},
+		{sent.MaxFuel, fp.Aircraft.MaxFuel},
+	}
+	for _, pair := range pairs {
+		if pair.sent == 0 {
+			continue
+		}
+		expected := ConvertWeight(pair.sent*1000, UnitsLBS, units)
+		if math.Abs(pair.echoed-expected) > expected*customAircraftTolerance {
+			return false
+		}
+	}
+	return true
+}
+
+// CruiseSpeed returns the planned cruise true airspeed (knots) and Mach number
+// Absent values are returned as zero without error; malformed values return an error
+func (fp *FlightPlanResponse) CruiseSpeed() (tas, mach float64, err error) {
+	if raw := strings.TrimSpace(fp.General.CruiseTAS); raw != "" {
+		if tas, err = ParseNumber(raw); err != nil {
+			return 0, 0, fmt.Errorf("invalid cruise TAS: %w", err)
+		}
+	}
+	if raw := strings.TrimSpace(fp.General.CruiseMach); raw != "" {
+		if mach, err = ParseNumber(raw); err != nil {
+			return 0, 0, fmt.Errorf("invalid cruise Mach: %w", err)
+		}
+	}
+	return tas, mach, nil
+}
+
+// ETOPS reports whether the plan was computed under ETOPS and the rule (diversion time in minutes)
+// The rule is zero when the plan is ETOPS but SimBrief did not report a parseable rule
+func (fp *FlightPlanResponse) ETOPS() (rule int, ok bool) {
+	switch strings.ToLower(strings.TrimSpace(fp.General.IsETOPS)) {
+	case "1", "true", "yes":
+	default:
+		return 0, false
+	}
+	if value, err := ParseNumber(fp.General.ETOPSRule); err == nil {
+		rule = int(value)
+	}
+	return rule, true
+}
+
+// LayoutMatches reports whether the fetched OFP layout matches the requested plan format
+// An empty requested format (account default) always matches
+func (fp *FlightPlanResponse) LayoutMatches(requested string) bool {
+	requested = strings.TrimSpace(requested)
+	if requested == "" {
+		return true
+	}
+	return strings.EqualFold(requested, strings.TrimSpace(fp.Params.OFPLayout))
+}
+
+// LandingFloat returns the planned landing fuel as a number
+func (f FuelInfo) LandingFloat() (float64, error) {
+	landing, err := ParseNumber(f.PlanLanding)
+	if err != nil {
+		return 0, fmt.Errorf("invalid landing fuel: %w", err)
+	}
+	return landing, nil
+}
+
+// CheckLandingFuel cross-checks the planned landing fuel against ramp - trip - taxi
+// and returns an error wrapping ErrFuelDiscrepancy when they differ by more than tolerance
+// Returns the planned landing fuel on success
+func (f FuelInfo) CheckLandingFuel(tolerance float64) (float64, error) {
+	landing, err := f.LandingFloat()
+	if err != nil {
+		return 0, err
+	}
+
+	ramp, err := ParseNumber(f.Plan)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ramp fuel: %w", err)
+	}
+	trip, err := ParseNumber(f.Trip)
+	if err != nil {
+		return 0, fmt.Errorf("invalid trip fuel: %w", err)
+	}
+	taxi, err := ParseNumber(f.Taxi)
+	if err != nil {
+		return 0, fmt.Errorf("invalid taxi fuel: %w", err)
+	}
+
+	expected := ramp - trip - taxi
+	if diff := landing - expected; diff > tolerance || diff < -tolerance {
+		return landing, fmt.Errorf("%w: planned landing %.0f, ramp - trip - taxi = %.0f",
+			ErrFuelDiscrepancy, landing, expected)
+	}
+
+	return landing, nil
+}
+
+// Validate reports critical sections missing from the fetched plan, as human-readable
+// warnings: an empty planned fuel, takeoff weight or route usually means generation failed.
+// An empty result means the plan is complete enough to brief.
+func (fp *FlightPlanResponse) Validate() []string {
+	var warnings []string
+	checks := []struct {
+		value   string
+		message string
+	}{
+		{fp.Fuel.Plan, "fuel section is missing the planned ramp fuel (plan_ramp)"},
+		{fp.Weights.TakeoffWt, "weights section is missing the takeoff weight (est_tow)"},
+		{fp.General.Route, "general section is missing the route"},
+	}
+	for _, check := range checks {
+		if strings.TrimSpace(check.value) == "" {
+			warnings = append(warnings, check.message)
+		}
+	}
+	return warnings
+}
+
+// Position returns the airport coordinates parsed from pos_lat and pos_long
+func (a AirportInfo) Position() (LatLon, error) {
+	lat, err := ParseNumber(a.Latitude)
+	if err != nil {
+		return LatLon{}, fmt.Errorf("invalid latitude for %s: %w", a.ICAO, err)
+	}
+	lon, err := ParseNumber(a.Longitude)
+	if err != nil {
+		return LatLon{}, fmt.Errorf("invalid longitude for %s: %w", a.ICAO, err)
+	}
+	return LatLon{Lat: lat, Lon: lon}, nil
+}
+
+// RouteEfficiency returns the great-circle distance between origin and destination as a
+// percentage of the planned air distance (General.Distance). 100 means a direct routing;
+// lower values mean a longer routing.
+func (fp *FlightPlanResponse) RouteEfficiency() (float64, error) {
+	planned, err := ParseNumber(fp.General.Distance)
+	if err != nil {
+		return 0, fmt.Errorf("invalid air distance: %w", err)
+	}
+	if planned <= 0 {
+		return 0, fmt.Errorf("air distance must be positive, got %g", planned)
+	}
+	origin, err := fp.Origin.Position()
+	if err != nil {
+		return 0, err
+	}
+	destination, err := fp.Destination.Position()
+	if err != nil {
+		return 0, err
+	}
+	return GreatCircleDistanceNM(origin, destination) / planned * 100, nil
+}
+
+// PlanUnits returns the weight units of the plan from General.Units, falling back to Params.Units
+func (fp *FlightPlanResponse) PlanUnits() (Units, error) {
+	units := fp.General.Units
+	if units == "" {
+		units = fp.Params.Units
+	}
+	switch Units(strings.ToUpper(string(units))) {
+	case UnitsLBS:
+		return UnitsLBS, nil
+	case UnitsKGS:
+		return UnitsKGS, nil
+	case "":
+		return "", fmt.Errorf("plan units are not specified")
+	default:
+		return "", fmt.Errorf("unknown plan units: %s", units)
+	}
+}
+
+// PayloadInUnits returns the planned payload together with the units it is expressed in
+func (fp *FlightPlanResponse) PayloadInUnits() (float64, Units, error) {
+	units, err := fp.PlanUnits()
+	if err != nil {
+		return 0, "", err
+	}
+	payload, err := ParseNumber(fp.Weights.Payload)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid payload: %w", err)
+	}
+	return payload, units, nil
+}
+
+// FuelBreakdown holds the planned fuel figures as numbers in a single unit system
+type FuelBreakdown struct {
+	Units       Units
+	Ramp        float64
+	Taxi        float64
+	Trip        float64
+	Contingency float64
+	Alternate   float64
+	Reserve     float64
+	Extra       float64
+	MinTakeoff  float64
+	PlanLanding float64
+	AvgFuelFlow float64 // Per hour
+	Tankering   float64
+}
+
+// FuelIn returns the planned fuel converted from the plan units (see PlanUnits) to units.
+// Empty figures are returned as zero; malformed figures return an error.
+func (fp *FlightPlanResponse) FuelIn(units Units) (FuelBreakdown, error) {
+	target := Units(strings.ToUpper(string(units)))
+	if target != UnitsLBS && target != UnitsKGS {
+		return FuelBreakdown{}, fmt.Errorf("unknown target units: %s", units)
+	}
+	source, err := fp.PlanUnits()
+	if err != nil {
+		return FuelBreakdown{}, err
+	}
+
+	breakdown := FuelBreakdown{Units: target}
+	fields := []struct {
+		name  string
+		raw   string
+		value *float64
+	}{
+		{"ramp", fp.Fuel.Plan, &breakdown.Ramp},
+		{"taxi", fp.Fuel.Taxi, &breakdown.Taxi},
+		{"trip", fp.Fuel.Trip, &breakdown.Trip},
+		{"contingency", fp.Fuel.Contingency, &breakdown.Contingency},
+		{"alternate", fp.Fuel.Alternate, &breakdown.Alternate},
+		{"reserve", fp.Fuel.Reserve, &breakdown.Reserve},
+		{"extra", fp.Fuel.Extra, &breakdown.Extra},
+		{"min takeoff", fp.Fuel.MinTakeoff, &breakdown.MinTakeoff},
+		{"planned landing", fp.Fuel.PlanLanding, &breakdown.PlanLanding},
+		{"average fuel flow", fp.Fuel.AvgFuelFlow, &breakdown.AvgFuelFlow},
+		{"tankering", fp.Fuel.Tankering, &breakdown.Tankering},
+	}
+	for _, field := range fields {
+		if strings.TrimSpace(field.raw) == "" {
+			continue
+		}
+		value, err := ParseNumber(field.raw)
+		if err != nil {
+			return FuelBreakdown{}, fmt.Errorf("invalid %s fuel: %w", field.name, err)
+		}
+		*field.value = ConvertWeight(value, source, target)
+	}
+	return breakdown, nil
+}
+
+// ContentHash returns a stable hash over the meaningful fields of the plan
+// (route, altitude, fuel, weights and alternates), ignoring volatile fields such as
+// the generation time or request ID, so pollers can detect real changes
+func (fp *FlightPlanResponse) ContentHash() string {
+	content := struct {
+		Origin      string
+		Destination string
+		Aircraft    string
+		Route       string
+		Altitude    string
+		Units       Units
+		Fuel        FuelInfo
+		Weights     WeightInfo
+		Alternates  []AlternateInfo
+	}{
+		Origin:      fp.Origin.ICAO,
+		Destination: fp.Destination.ICAO,
+		Aircraft:    fp.Aircraft.ICAO,
+		Route:       fp.General.Route,
+		Altitude:    fp.General.CruiseAltitude,
+		Units:       fp.General.Units,
+		Fuel:        fp.Fuel,
+		Weights:     fp.Weights,
+		Alternates:  fp.alternates(),
+	}
+
+	// Struct fields marshal in declaration order, which keeps the encoding stable
+	data, _ := json.Marshal(content)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// GeneratedAt returns the generation time of the plan from Params.TimeGen (Unix seconds)
+func (fp *FlightPlanResponse) GeneratedAt() (time.Time, error) {
+	raw := strings.TrimSpace(fp.Params.TimeGen)
+	if raw == "" {
+		return time.Time{}, fmt.Errorf("plan has no generation time")
+	}
+	seconds, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid generation time %q: %w", raw, err)
+	}
+	return time.Unix(seconds, 0).UTC(), nil
+}
@@ -0,0 +1,118 @@
+package types
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// FieldDiff describes a field that differs between two flight plans
+type FieldDiff struct {
+	Path string // Field path, e.g. "Fuel.Plan"
+	Old  string // Value in the first plan
+	New  string // Value in the second plan
+}
+
+// DiffOption configures DiffFlightPlans
+type DiffOption func(*diffConfig)
+
+type diffConfig struct {
+	includeVolatile bool
+}
+
+// IncludeVolatile also compares fields that change on every generation (TimeGen, RequestID, ...)
+func IncludeVolatile() DiffOption {
+	return func(c *diffConfig) { c.includeVolatile = true }
+}
+
+// volatileFields lists the field paths ignored by default
+var volatileFields = map[string]bool{
+	"Params.RequestID": true,
+	"Params.TimeGen":   true,
+	"Params.StaticID":  true,
+	"Params.XMLFile":   true,
+}
+
+// DiffFlightPlans compares the key sections of two flight plans (params, general, aircraft,
+// airports, fuel, weights and times) and returns the differing fields in declaration order.
+// Navlog, files and links are not compared.
+func DiffFlightPlans(a, b *FlightPlanResponse, opts ...DiffOption) []FieldDiff {
+	cfg := &diffConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if a == nil {
+		a = &FlightPlanResponse{}
+	}
+	if b == nil {
+		b = &FlightPlanResponse{}
+	}
+
+	type section struct {
+		name string
+		a, b interface{}
+	}
+	sections := []section{
+		{"Params", a.Params, b.Params},
+		{"General", a.General, b.General},
+		{"Aircraft", a.Aircraft, b.Aircraft},
+		{"Origin", a.Origin, b.Origin},
+		{"Destination", a.Destination, b.Destination},
+	}
+
+	// Alternates are compared by position, a missing entry on either side diffs as empty
+	altsA, altsB := a.alternates(), b.alternates()
+	for i := 0; i < len(altsA) || i < len(altsB); i++ {
+		var altA, altB AlternateInfo
+		if i < len(altsA) {
+			altA = altsA[i]
+		}
+		if i < len(altsB) {
+			altB = altsB[i]
+		}
+		sections = append(sections, section{fmt.Sprintf("Alternates[%d]", i), altA, altB})
+	}
+
+	sections = append(sections,
+		section{"Fuel", a.Fuel, b.Fuel},
+		section{"Weights", a.Weights, b.Weights},
+		section{"Times", a.Times, b.Times},
+	)
+
+	var diffs []FieldDiff
+	for _, section := range sections {
+		diffs = diffValues(section.name, reflect.ValueOf(section.a), reflect.ValueOf(section.b), cfg, diffs)
+	}
+	return diffs
+}
+
+// diffValues recursively compares leaf fields of two values of the same type
+func diffValues(path string, a, b reflect.Value, cfg *diffConfig, diffs []FieldDiff) []FieldDiff {
+	if !cfg.includeVolatile && volatileFields[path] {
+		return diffs
+	}
+
+	if a.Kind() == reflect.Struct {
+		if _, ok := a.Interface().(fmt.Stringer); !ok {
+			for i := 0; i < a.NumField(); i++ {
+				field := a.Type().Field(i)
+				if !field.IsExported() {
+					continue
+				}
+				diffs = diffValues(path+"."+field.Name, a.Field(i), b.Field(i), cfg, diffs)
+			}
+			return diffs
+		}
+	}
+
+	switch a.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Ptr, reflect.Interface:
+		return diffs
+	}
+
+	oldValue, newValue := fmt.Sprint(a.Interface()), fmt.Sprint(b.Interface())
+	if oldValue != newValue {
+		diffs = append(diffs, FieldDiff{Path: path, Old: oldValue, New: newValue})
+	}
+	return diffs
+}
@@ -1,5 +1,10 @@
 package types
 
+import (
+	"math"
+	"strings"
+)
+
 // Units represents the weight/fuel units
 type Units string
 
@@ -8,14 +13,69 @@ const (
 	UnitsKGS Units = "KGS"
 )
 
+// KGSPerLB is the number of kilograms in one pound
+const KGSPerLB = 0.453592
+
+// ConvertWeight converts a weight between unit systems, rounded to 3 decimal places
+// Values are returned unchanged when either unit is empty or both are equal
+func ConvertWeight(value float64, from, to Units) float64 {
+	switch {
+	case from == UnitsLBS && to == UnitsKGS:
+		value *= KGSPerLB
+	case from == UnitsKGS && to == UnitsLBS:
+		value /= KGSPerLB
+	default:
+		return value
+	}
+	return math.Round(value*1000) / 1000
+}
+
 // PlanFormat represents the OFP layout format
 type PlanFormat string
 
+// Common layouts from the SimBrief inputs list; GetPlanFormats returns the full set
 const (
 	PlanFormatLIDO    PlanFormat = "LIDO"
 	PlanFormatDefault PlanFormat = ""
+	PlanFormatAAL     PlanFormat = "AAL" // American Airlines
+	PlanFormatACA     PlanFormat = "ACA" // Air Canada
+	PlanFormatAFR     PlanFormat = "AFR" // Air France
+	PlanFormatAWE     PlanFormat = "AWE" // America West
+	PlanFormatBAW     PlanFormat = "BAW" // British Airways
+	PlanFormatDAL     PlanFormat = "DAL" // Delta Air Lines
+	PlanFormatDLH     PlanFormat = "DLH" // Lufthansa
+	PlanFormatEZY     PlanFormat = "EZY" // easyJet
+	PlanFormatJBU     PlanFormat = "JBU" // JetBlue
+	PlanFormatKLM     PlanFormat = "KLM" // KLM
+	PlanFormatQFA     PlanFormat = "QFA" // Qantas
+	PlanFormatRYR     PlanFormat = "RYR" // Ryanair
+	PlanFormatSWA     PlanFormat = "SWA" // Southwest Airlines
+	PlanFormatUAE     PlanFormat = "UAE" // Emirates
+	PlanFormatUAL     PlanFormat = "UAL" // United Airlines
 )
 
+var knownPlanFormats = []PlanFormat{
+	PlanFormatLIDO, PlanFormatAAL, PlanFormatACA, PlanFormatAFR, PlanFormatAWE,
+	PlanFormatBAW, PlanFormatDAL, PlanFormatDLH, PlanFormatEZY, PlanFormatJBU,
+	PlanFormatKLM, PlanFormatQFA, PlanFormatRYR, PlanFormatSWA, PlanFormatUAE,
+	PlanFormatUAL,
+}
+
+// ParsePlanFormat matches s case-insensitively against the known layout constants
+// An empty string yields PlanFormatDefault
+func ParsePlanFormat(s string) (PlanFormat, bool) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if s == "" {
+		return PlanFormatDefault, true
+	}
+	for _, format := range knownPlanFormats {
+		if string(format) == s {
+			return format, true
+		}
+	}
+	return "", false
+}
+
 // FlightRules represents IFR/VFR rules
 type FlightRules string
 
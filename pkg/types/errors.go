@@ -1,13 +1,72 @@
 package types
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 // Common validation errors
 var (
-	ErrMissingOrigin      = errors.New("origin airport (orig) is required")
-	ErrMissingDestination = errors.New("destination airport (dest) is required")
-	ErrMissingAircraft    = errors.New("aircraft type (type) is required")
-	ErrMissingUserID      = errors.New("user ID or username is required")
-	ErrInvalidUserID      = errors.New("invalid user ID format")
-	ErrInvalidAPIKey      = errors.New("invalid or missing API key")
+	ErrMissingOrigin       = errors.New("origin airport (orig) is required")
+	ErrMissingDestination  = errors.New("destination airport (dest) is required")
+	ErrMissingAircraft     = errors.New("aircraft type (type) is required")
+	ErrMissingUserID       = errors.New("user ID or username is required")
+	ErrInvalidUserID       = errors.New("invalid user ID format")
+	ErrInvalidAPIKey       = errors.New("invalid or missing API key")
+	ErrMissingAircraftData = errors.New("aircraft data is required")
+	ErrExceedsMTOW         = errors.New("takeoff weight exceeds maximum takeoff weight (MTOW)")
+	ErrInvalidFlightNumber = errors.New("invalid flight number")
+	ErrNoAlternate         = errors.New("no alternate planned")
+	ErrAPIUnavailable      = errors.New("SimBrief API is unavailable")
+	ErrConflictingUserIDs  = errors.New("user ID and username cannot be combined")
+	ErrFuelDiscrepancy     = errors.New("fuel figures are inconsistent")
+	ErrNoKMLFile           = errors.New("flight plan has no KML file")
+	ErrInvalidRunway       = errors.New("invalid runway identifier")
+	ErrExtraParamConflict  = errors.New("extra parameter conflicts with a modeled parameter")
 )
+
+// GenerateTimeoutError is returned when a generated flight plan does not become available in time
+type GenerateTimeoutError struct {
+	StaticID string
+	Waited   time.Duration
+	Err      error // Underlying context error
+	LastErr  error // Last fetch error seen while polling, if any
+}
+
+func (e *GenerateTimeoutError) Error() string {
+	message := fmt.Sprintf("flight plan %q not available after %s", e.StaticID, e.Waited.Round(time.Millisecond))
+	if e.LastErr != nil {
+		message += fmt.Sprintf(" (last fetch error: %v)", e.LastErr)
+	}
+	return message
+}
+
+// Unwrap returns the underlying context error and the last fetch error
+func (e *GenerateTimeoutError) Unwrap() []error {
+	if e.LastErr == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Err, e.LastErr}
+}
+
+// HTTPError is returned when SimBrief answers with a non-200 status or an HTML error page.
+// Use errors.As to inspect the status code; errors.As with APIError still matches parsed API errors.
+type HTTPError struct {
+	StatusCode int
+	Body       string
+	URL        string // Request URL with the API key redacted
+	Err        error  // Parsed API error, if any
+}
+
+func (e *HTTPError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("API request failed with status %d: %v", e.StatusCode, e.Err)
+	}
+	return fmt.Sprintf("API request failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+// Unwrap returns the parsed API error
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
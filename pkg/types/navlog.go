@@ -0,0 +1,422 @@
+package types
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NavLog contains the navigation log fixes of the flight plan
+type NavLog struct {
+	Fixes []NavLogFix `xml:"fix" json:"fix"`
+
+	// raw holds the original JSON payload, see FlightPlanResponse.RawNavLog
+	raw json.RawMessage
+	// rawXML holds the original XML element, see FlightPlanResponse.RawNavLogXML
+	rawXML []byte
+}
+
+// UnmarshalXML implements custom XML unmarshaling for NavLog, keeping the original element
+func (n *NavLog) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var inner struct {
+		XML []byte `xml:",innerxml"`
+	}
+	if err := d.DecodeElement(&inner, &start); err != nil {
+		return err
+	}
+
+	raw := append(append([]byte("<navlog>"), inner.XML...), "</navlog>"...)
+	var plain struct {
+		Fixes []NavLogFix `xml:"fix"`
+	}
+	if err := xml.Unmarshal(raw, &plain); err != nil {
+		return err
+	}
+
+	n.Fixes = plain.Fixes
+	n.rawXML = raw
+	return nil
+}
+
+// UnmarshalJSON implements custom JSON unmarshaling for NavLog
+// SimBrief returns a single object instead of an array when the log contains one fix
+func (n *NavLog) UnmarshalJSON(data []byte) error {
+	var wrapper struct {
+		Fix json.RawMessage `json:"fix"`
+	}
+	if err := json.Unmarshal(data, &wrapper); err != nil {
+		return fmt.Errorf("navlog must be an object: %w", err)
+	}
+
+	n.raw = append(json.RawMessage(nil), data...)
+	fixes, err := unmarshalOneOrMany[NavLogFix](wrapper.Fix)
+	n.Fixes = fixes
+	return err
+}
+
+// LatLon is a geographic coordinate in decimal degrees
+type LatLon struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// EncodePolyline returns the nav log fix coordinates as a Google encoded polyline
+// (precision 5), as consumed by Leaflet and Mapbox
+func (fp *FlightPlanResponse) EncodePolyline() (string, error) {
+	fixes := fp.NavLogFixes()
+	if len(fixes) == 0 {
+		return "", fmt.Errorf("flight plan has no navlog fixes")
+	}
+
+	var b strings.Builder
+	var prevLat, prevLon int64
+	for _, fix := range fixes {
+		lat := int64(math.Round(fix.Latitude * 1e5))
+		lon := int64(math.Round(fix.Longitude * 1e5))
+		encodePolylineValue(&b, lat-prevLat)
+		encodePolylineValue(&b, lon-prevLon)
+		prevLat, prevLon = lat, lon
+	}
+	return b.String(), nil
+}
+
+// encodePolylineValue appends one zigzag-encoded delta in 5-bit chunks
+func encodePolylineValue(b *strings.Builder, value int64) {
+	v := value << 1
+	if value < 0 {
+		v = ^v
+	}
+	for v >= 0x20 {
+		b.WriteByte(byte((0x20 | (v & 0x1f)) + 63))
+		v >>= 5
+	}
+	b.WriteByte(byte(v + 63))
+}
+
+// geoJSONFeatureCollection is the root object of a GeoJSON document
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   geoJSONGeometry        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type geoJSONGeometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+// ExportGeoJSON exports the nav log as a GeoJSON FeatureCollection: a LineString of the
+// route followed by a Point per fix with ident, type and altitude (feet) properties.
+// A plan without nav log yields an empty FeatureCollection.
+func (fp *FlightPlanResponse) ExportGeoJSON() ([]byte, error) {
+	fixes := fp.NavLogFixes()
+	collection := geoJSONFeatureCollection{Type: "FeatureCollection", Features: []geoJSONFeature{}}
+
+	line := make([][2]float64, 0, len(fixes))
+	points := make([]geoJSONFeature, 0, len(fixes))
+	for _, fix := range fixes {
+		position := [2]float64{fix.Longitude, fix.Latitude} // GeoJSON order is lon, lat
+		line = append(line, position)
+		points = append(points, geoJSONFeature{
+			Type:       "Feature",
+			Geometry:   geoJSONGeometry{Type: "Point", Coordinates: position},
+			Properties: map[string]interface{}{"ident": fix.Ident, "type": fix.Type, "altitude": fix.Altitude},
+		})
+	}
+
+	// A LineString needs at least two positions
+	if len(line) >= 2 {
+		collection.Features = append(collection.Features, geoJSONFeature{
+			Type:       "Feature",
+			Geometry:   geoJSONGeometry{Type: "LineString", Coordinates: line},
+			Properties: map[string]interface{}{"origin": fp.Origin.ICAO, "destination": fp.Destination.ICAO},
+		})
+	}
+	collection.Features = append(collection.Features, points...)
+
+	data, err := json.Marshal(collection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode GeoJSON: %w", err)
+	}
+	return data, nil
+}
+
+// earthRadiusNM is the mean Earth radius in nautical miles
+const earthRadiusNM = 3440.065
+
+// GreatCircleDistanceNM returns the great-circle distance between two coordinates in
+// nautical miles, using the haversine formula on a spherical Earth
+func GreatCircleDistanceNM(a, b LatLon) float64 {
+	lat1, lat2 := a.Lat*math.Pi/180, b.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (b.Lon - a.Lon) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusNM * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// numericFixFields lists the NavLogFix JSON keys decoded into numbers
+var numericFixFields = []string{
+	"pos_lat", "pos_long", "distance_nm", "track_true", "track_mag",
+	"altitude_feet", "mora", "oat", "fuel_flow", "fuel_totalused",
+}
+
+// UnmarshalJSON implements custom JSON unmarshaling for NavLogFix
+// SimBrief encodes numeric values as strings, so they are converted before decoding
+func (f *NavLogFix) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	for _, key := range numericFixFields {
+		raw, ok := fields[key]
+		if !ok {
+			continue
+		}
+		var str string
+		if err := json.Unmarshal(raw, &str); err != nil {
+			continue // already a number
+		}
+		if strings.TrimSpace(str) == "" {
+			delete(fields, key)
+			continue
+		}
+		number, err := ParseNumber(str)
+		if err != nil {
+			return fmt.Errorf("invalid numeric value for %s: %q", key, str)
+		}
+		fields[key] = json.RawMessage(strconv.FormatFloat(number, 'f', -1, 64))
+	}
+
+	normalized, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+
+	type plain NavLogFix
+	return json.Unmarshal(normalized, (*plain)(f))
+}
+
+// NavLogFixes returns the navigation log fixes, or nil when no navlog was returned
+func (fp *FlightPlanResponse) NavLogFixes() []NavLogFix {
+	if fp.NavLog == nil {
+		return nil
+	}
+	return fp.NavLog.Fixes
+}
+
+// RawNavLog returns the original navlog JSON payload, letting callers decode fields the
+// SDK does not model. It is JSON-only: it returns nil when there is no navlog or the plan
+// was decoded from XML, see RawNavLogXML.
+func (fp *FlightPlanResponse) RawNavLog() json.RawMessage {
+	if fp.NavLog == nil {
+		return nil
+	}
+	return fp.NavLog.raw
+}
+
+// RawNavLogXML returns the original <navlog> element of a plan decoded from XML.
+// Returns nil when there is no navlog or the plan was decoded from JSON, see RawNavLog.
+func (fp *FlightPlanResponse) RawNavLogXML() []byte {
+	if fp.NavLog == nil {
+		return nil
+	}
+	return fp.NavLog.rawXML
+}
+
+// EachFix calls fn for every navigation log fix in order, stopping at the first error
+// A missing navlog results in zero iterations and a nil error
+func (fp *FlightPlanResponse) EachFix(fn func(i int, f NavLogFix) error) error {
+	for i, fix := range fp.NavLogFixes() {
+		if err := fn(i, fix); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TopOfClimb returns the top-of-climb pseudo-waypoint, matched by type or ident "TOC"
+func (fp *FlightPlanResponse) TopOfClimb() (*NavLogFix, bool) {
+	return fp.findPseudoFix("TOC")
+}
+
+// TopOfDescent returns the top-of-descent pseudo-waypoint, matched by type or ident "TOD"
+func (fp *FlightPlanResponse) TopOfDescent() (*NavLogFix, bool) {
+	return fp.findPseudoFix("TOD")
+}
+
+// findPseudoFix returns the first fix whose type or ident equals label (case-insensitive)
+func (fp *FlightPlanResponse) findPseudoFix(label string) (*NavLogFix, bool) {
+	fixes := fp.NavLogFixes()
+	for i := range fixes {
+		if strings.EqualFold(fixes[i].Type, label) || strings.EqualFold(fixes[i].Ident, label) {
+			return &fixes[i], true
+		}
+	}
+	return nil, false
+}
+
+// ValidateFuelMonotonic checks that cumulative fuel used (FuelRemain, fuel_totalused) never
+// decreases between successive fixes. The first offending pair is reported in an error
+// wrapping ErrFuelDiscrepancy.
+func (fp *FlightPlanResponse) ValidateFuelMonotonic() error {
+	fixes := fp.NavLogFixes()
+	for i := 1; i < len(fixes); i++ {
+		prev, cur := fixes[i-1], fixes[i]
+		if cur.FuelRemain < prev.FuelRemain {
+			return fmt.Errorf("%w: fuel used decreases from %g at %s (fix %d) to %g at %s (fix %d)",
+				ErrFuelDiscrepancy, prev.FuelRemain, prev.Ident, i-1, cur.FuelRemain, cur.Ident, i)
+		}
+	}
+	return nil
+}
+
+// SumLegTimes sums the leg times (ETE, time_leg) of all nav log fixes, for comparison with
+// Times.FlightTime. Fixes with an empty leg time, such as the departure, count as zero.
+func (fp *FlightPlanResponse) SumLegTimes() (time.Duration, error) {
+	var total time.Duration
+	for _, fix := range fp.NavLogFixes() {
+		if strings.TrimSpace(fix.ETE) == "" {
+			continue
+		}
+		leg, err := parseDuration(fix.ETE)
+		if err != nil {
+			return 0, fmt.Errorf("fix %s: %w", fix.Ident, err)
+		}
+		total += leg
+	}
+	return total, nil
+}
+
+// PhaseTimes sums the leg times (ETE) of the nav log by stage into climb, cruise and
+// descent durations. Fixes without a leg time, such as the departure, are skipped.
+func (fp *FlightPlanResponse) PhaseTimes() (climb, cruise, descent time.Duration, err error) {
+	fixes := fp.NavLogFixes()
+	if len(fixes) == 0 {
+		return 0, 0, 0, fmt.Errorf("flight plan has no nav log")
+	}
+
+	for _, fix := range fixes {
+		if strings.TrimSpace(fix.ETE) == "" {
+			continue
+		}
+		leg, err := parseDuration(fix.ETE)
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("fix %s: %w", fix.Ident, err)
+		}
+
+		switch strings.ToUpper(strings.TrimSpace(fix.Stage)) {
+		case "CLB":
+			climb += leg
+		case "CRZ":
+			cruise += leg
+		case "DSC", "DES":
+			descent += leg
+		default:
+			return 0, 0, 0, fmt.Errorf("fix %s: unknown stage %q", fix.Ident, fix.Stage)
+		}
+	}
+	return climb, cruise, descent, nil
+}
+
+// ComputedAvgFuelFlow returns the nav log fuel flow averaged over the flight, weighting each
+// leg by its leg time (ETE), for comparison with Fuel.AvgFuelFlow. Legs without a fuel flow
+// or leg time are skipped.
+func (fp *FlightPlanResponse) ComputedAvgFuelFlow() (float64, error) {
+	var weighted, total float64
+
+	for _, fix := range fp.NavLogFixes() {
+		if fix.FuelFlow <= 0 || strings.TrimSpace(fix.ETE) == "" {
+			continue
+		}
+		leg, err := parseDuration(fix.ETE)
+		if err != nil {
+			return 0, fmt.Errorf("fix %s: %w", fix.Ident, err)
+		}
+		weighted += fix.FuelFlow * leg.Seconds()
+		total += leg.Seconds()
+	}
+
+	if total == 0 {
+		return 0, fmt.Errorf("nav log has no legs with fuel flow")
+	}
+	return weighted / total, nil
+}
+
+// AverageHeadwind returns the headwind component averaged over the nav log, weighting each
+// leg by its distance. Positive values are headwinds, negative values tailwinds (knots).
+// Legs without wind data or distance are skipped.
+func (fp *FlightPlanResponse) AverageHeadwind() (float64, error) {
+	var weighted, total float64
+
+	for _, fix := range fp.NavLogFixes() {
+		if fix.Distance <= 0 || strings.TrimSpace(fix.Wind) == "" {
+			continue
+		}
+		head, _, err := fix.WindComponents()
+		if err != nil {
+			return 0, fmt.Errorf("fix %s: %w", fix.Ident, err)
+		}
+		weighted += head * fix.Distance
+		total += fix.Distance
+	}
+
+	if total == 0 {
+		return 0, fmt.Errorf("nav log has no legs with wind data")
+	}
+	return weighted / total, nil
+}
+
+// WindComponents returns the headwind and crosswind components of the fix wind
+// ("270/45", "27045" or calm "00000") relative to the leg's true track, in knots.
+// Headwind is positive against the direction of flight (negative means tailwind),
+// crosswind is positive from the right.
+func (f NavLogFix) WindComponents() (head, cross float64, err error) {
+	return windComponents(f.Track, f.Wind)
+}
+
+// windComponents splits a wind ("270/45", "27045" or calm "00000") into the headwind and
+// crosswind components relative to the given true track. Headwind is positive against the
+// direction of flight, crosswind is positive from the right.
+func windComponents(track float64, wind string) (head, cross float64, err error) {
+	direction, speed, err := parseWind(wind)
+	if err != nil {
+		return 0, 0, err
+	}
+	if speed == 0 {
+		return 0, 0, nil
+	}
+
+	angle := (direction - track) * math.Pi / 180
+	return speed * math.Cos(angle), speed * math.Sin(angle), nil
+}
+
+// parseWind parses "DDD/SS" or "DDDSS[S]" wind strings into direction (degrees) and speed (knots)
+func parseWind(wind string) (direction, speed float64, err error) {
+	wind = strings.TrimSpace(wind)
+
+	var dirPart, spdPart string
+	if d, s, ok := strings.Cut(wind, "/"); ok {
+		dirPart, spdPart = d, s
+	} else if len(wind) >= 5 {
+		dirPart, spdPart = wind[:3], wind[3:]
+	} else {
+		return 0, 0, fmt.Errorf("invalid wind %q", wind)
+	}
+
+	direction, errDir := strconv.ParseFloat(strings.TrimSpace(dirPart), 64)
+	speed, errSpd := strconv.ParseFloat(strings.TrimSpace(spdPart), 64)
+	if errDir != nil || errSpd != nil || direction < 0 || direction > 360 || speed < 0 {
+		return 0, 0, fmt.Errorf("invalid wind %q", wind)
+	}
+	return direction, speed, nil
+}
@@ -0,0 +1,158 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ParseNumber parses a numeric string from the API response tolerantly:
+// surrounding and embedded whitespace and comma thousands separators are removed
+// before parsing, so "10,000.5" and " 10 000 " are accepted. Commas that do not
+// separate 3-digit groups, such as a decimal comma in "10,5", are an error.
+func ParseNumber(value string) (float64, error) {
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, value)
+
+	if cleaned == "" {
+		return 0, fmt.Errorf("empty value")
+	}
+
+	if strings.Contains(cleaned, ",") {
+		if !validThousands(cleaned) {
+			return 0, fmt.Errorf("invalid number %q", value)
+		}
+		cleaned = strings.ReplaceAll(cleaned, ",", "")
+	}
+
+	number, err := strconv.ParseFloat(cleaned, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", value)
+	}
+	return number, nil
+}
+
+// validThousands reports whether the commas in number only group the integer part
+// into thousands, e.g. "-1,234,567.5"
+func validThousands(number string) bool {
+	integer, fraction, _ := strings.Cut(strings.TrimLeft(number, "+-"), ".")
+	if strings.Contains(fraction, ",") {
+		return false
+	}
+	groups := strings.Split(integer, ",")
+	for i, group := range groups {
+		if (i == 0 && (len(group) == 0 || len(group) > 3)) || (i > 0 && len(group) != 3) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseDuration parses a SimBrief time value, either a number of seconds ("22620")
+// or an "HH:MM" string ("06:17")
+func parseDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, fmt.Errorf("empty value")
+	}
+
+	if hours, minutes, ok := strings.Cut(value, ":"); ok {
+		h, errH := strconv.Atoi(hours)
+		m, errM := strconv.Atoi(minutes)
+		if errH != nil || errM != nil || h < 0 || m < 0 || m > 59 {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+	}
+
+	seconds, err := ParseNumber(value)
+	if err != nil || seconds < 0 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// parseOptionalNumber is ParseNumber that returns zero for an empty or blank value
+func parseOptionalNumber(value string) (float64, error) {
+	if strings.TrimSpace(value) == "" {
+		return 0, nil
+	}
+	return ParseNumber(value)
+}
+
+// NumericWeights holds the WeightInfo figures as numbers, in the plan units
+type NumericWeights struct {
+	OEW       float64
+	Payload   float64
+	PaxCount  int
+	PaxWeight float64
+	BagWeight float64
+	Cargo     float64
+	ZFW       float64
+	TakeoffWt float64
+	LandingWt float64
+}
+
+// Numeric parses every weight figure in one pass. Empty values are returned as zero;
+// the first malformed value returns an error naming the field.
+func (w WeightInfo) Numeric() (NumericWeights, error) {
+	var n NumericWeights
+	var paxCount float64
+	fields := []struct {
+		name  string
+		raw   string
+		value *float64
+	}{
+		{"OEW", w.OEW, &n.OEW},
+		{"payload", w.Payload, &n.Payload},
+		{"pax count", w.PaxCount, &paxCount},
+		{"pax weight", w.PaxWeight, &n.PaxWeight},
+		{"bag weight", w.BagWeight, &n.BagWeight},
+		{"cargo", w.Cargo, &n.Cargo},
+		{"ZFW", w.ZFW, &n.ZFW},
+		{"takeoff weight", w.TakeoffWt, &n.TakeoffWt},
+		{"landing weight", w.LandingWt, &n.LandingWt},
+	}
+	for _, field := range fields {
+		value, err := parseOptionalNumber(field.raw)
+		if err != nil {
+			return NumericWeights{}, fmt.Errorf("invalid %s: %w", field.name, err)
+		}
+		*field.value = value
+	}
+	if paxCount != float64(int(paxCount)) {
+		return NumericWeights{}, fmt.Errorf("invalid pax count %q: not a whole number", w.PaxCount)
+	}
+	n.PaxCount = int(paxCount)
+	return n, nil
+}
+
+// PaxCountInt returns the passenger count, zero when empty
+func (w WeightInfo) PaxCountInt() (int, error) {
+	n, err := WeightInfo{PaxCount: w.PaxCount}.Numeric()
+	return n.PaxCount, err
+}
+
+// PaxWeightFloat returns the passenger weight, zero when empty
+func (w WeightInfo) PaxWeightFloat() (float64, error) {
+	n, err := WeightInfo{PaxWeight: w.PaxWeight}.Numeric()
+	return n.PaxWeight, err
+}
+
+// BagWeightFloat returns the baggage weight, zero when empty
+func (w WeightInfo) BagWeightFloat() (float64, error) {
+	n, err := WeightInfo{BagWeight: w.BagWeight}.Numeric()
+	return n.BagWeight, err
+}
+
+// CargoFloat returns the cargo weight, zero when empty
+func (w WeightInfo) CargoFloat() (float64, error) {
+	n, err := WeightInfo{Cargo: w.Cargo}.Numeric()
+	return n.Cargo, err
+}
@@ -1,105 +1,119 @@
 package types
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/url"
+	"reflect"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // FlightPlanRequest represents all possible parameters for generating a flight plan
 // Based on official SimBrief API documentation at https://developers.navigraph.com/docs/simbrief/using-the-api
 type FlightPlanRequest struct {
 	// Required parameters
-	Origin      string `form:"orig"` // ICAO origin airport (required)
-	Destination string `form:"dest"` // ICAO destination airport (required)
-	Aircraft    string `form:"type"` // Aircraft type (required)
+	Origin      string `form:"orig" json:"orig,omitempty"` // ICAO origin airport (required)
+	Destination string `form:"dest" json:"dest,omitempty"` // ICAO destination airport (required)
+	Aircraft    string `form:"type" json:"type,omitempty"` // Aircraft type (required)
 
 	// Basic flight information
-	Airline         string `form:"airline"` // Airline code (e.g., "ABC")
-	FlightNumber    string `form:"fltnum"`  // Flight number (e.g., "1234")
-	Date            string `form:"date"`    // Date format: 11JUL13
-	DepartureHour   int    `form:"deph"`    // Departure hour (0-23)
-	DepartureMinute int    `form:"depm"`    // Departure minute (0-59)
-	Route           string `form:"route"`   // Flight route (e.g., "PLL GAROT OAL MOD4")
-	ScheduledHour   int    `form:"steh"`    // Scheduled time hour
-	ScheduledMinute int    `form:"stem"`    // Scheduled time minute
+	Airline         string `form:"airline" json:"airline,omitempty"` // Airline code (e.g., "ABC")
+	FlightNumber    string `form:"fltnum" json:"fltnum,omitempty"`   // Flight number (e.g., "1234")
+	Date            string `form:"date" json:"date,omitempty"`       // Date format: 11JUL13
+	DepartureHour   *int   `form:"deph" json:"deph,omitempty"`       // Departure hour (0-23)
+	DepartureMinute *int   `form:"depm" json:"depm,omitempty"`       // Departure minute (0-59)
+	Route           string `form:"route" json:"route,omitempty"`     // Flight route (e.g., "PLL GAROT OAL MOD4")
+	ScheduledHour   *int   `form:"steh" json:"steh,omitempty"`       // Scheduled time enroute, hours
+	ScheduledMinute *int   `form:"stem" json:"stem,omitempty"`       // Scheduled time enroute, minutes (0-59)
 
 	// Aircraft details
-	Registration string `form:"reg"`      // Aircraft registration (e.g., "N123XX")
-	FinNumber    string `form:"fin"`      // Aircraft fin number (e.g., "123")
-	SELCAL       string `form:"selcal"`   // Aircraft SELCAL (e.g., "ABCD")
-	ATCCallsign  string `form:"callsign"` // ATC callsign (e.g., "ABC1234")
+	Registration string `form:"reg" json:"reg,omitempty"`           // Aircraft registration (e.g., "N123XX")
+	FinNumber    string `form:"fin" json:"fin,omitempty"`           // Aircraft fin number (e.g., "123")
+	SELCAL       string `form:"selcal" json:"selcal,omitempty"`     // Aircraft SELCAL (e.g., "ABCD")
+	ATCCallsign  string `form:"callsign" json:"callsign,omitempty"` // ATC callsign (e.g., "ABC1234")
 
 	// Crew and passenger info
-	Passengers     int    `form:"pax"`    // Number of passengers (e.g., 100)
-	CaptainName    string `form:"cpt"`    // Captain's name (e.g., "JOHN DOE")
-	DispatcherName string `form:"dxname"` // Dispatcher's name (e.g., "JANE DOE")
-	PilotID        string `form:"pid"`    // Pilot ID number (e.g., "12345")
+	Passengers     int    `form:"pax" json:"pax,omitempty"`           // Number of passengers (e.g., 100)
+	CaptainName    string `form:"cpt" json:"cpt,omitempty"`           // Captain's name (e.g., "JOHN DOE")
+	DispatcherName string `form:"dxname" json:"dxname,omitempty"`     // Dispatcher's name (e.g., "JANE DOE")
+	PilotID        string `form:"pid" json:"pid,omitempty"`           // Pilot ID number (e.g., "12345")
+	Username       string `form:"username" json:"username,omitempty"` // SimBrief username the generated plan is associated with
 
 	// Alternates and routing
-	Alternate string `form:"altn"`       // Primary alternate airport (e.g., "KLAX")
-	Altitude  string `form:"fl"`         // Cruise altitude (e.g., "34000", "FL340")
-	AltnCount int    `form:"altn_count"` // Number of alternates (e.g., 4)
-	AltnAvoid string `form:"altn_avoid"` // Avoid alternate airports (e.g., "KJFK KPHL KBWI")
+	Alternate string `form:"altn" json:"altn,omitempty"`             // Primary alternate airport (e.g., "KLAX")
+	Altitude  string `form:"fl" json:"fl,omitempty"`                 // Cruise altitude (e.g., "34000", "FL340")
+	AltnCount int    `form:"altn_count" json:"altn_count,omitempty"` // Number of alternates (e.g., 4)
+	AltnAvoid string `form:"altn_avoid" json:"altn_avoid,omitempty"` // Avoid alternate airports (e.g., "KJFK KPHL KBWI")
 
 	// Alternate 1-4 specific fields
-	Altn1ID     string `form:"altn_1_id"`    // Alternate 1 identifier
-	Altn1Runway string `form:"altn_1_rwy"`   // Alternate 1 runway
-	Altn1Route  string `form:"altn_1_route"` // Alternate 1 routing
-	Altn2ID     string `form:"altn_2_id"`    // Alternate 2 identifier
-	Altn2Runway string `form:"altn_2_rwy"`   // Alternate 2 runway
-	Altn2Route  string `form:"altn_2_route"` // Alternate 2 routing
-	Altn3ID     string `form:"altn_3_id"`    // Alternate 3 identifier
-	Altn3Runway string `form:"altn_3_rwy"`   // Alternate 3 runway
-	Altn3Route  string `form:"altn_3_route"` // Alternate 3 routing
-	Altn4ID     string `form:"altn_4_id"`    // Alternate 4 identifier
-	Altn4Runway string `form:"altn_4_rwy"`   // Alternate 4 runway
-	Altn4Route  string `form:"altn_4_route"` // Alternate 4 routing
+	Altn1ID     string `form:"altn_1_id" json:"altn_1_id,omitempty"`       // Alternate 1 identifier
+	Altn1Runway string `form:"altn_1_rwy" json:"altn_1_rwy,omitempty"`     // Alternate 1 runway
+	Altn1Route  string `form:"altn_1_route" json:"altn_1_route,omitempty"` // Alternate 1 routing
+	Altn2ID     string `form:"altn_2_id" json:"altn_2_id,omitempty"`       // Alternate 2 identifier
+	Altn2Runway string `form:"altn_2_rwy" json:"altn_2_rwy,omitempty"`     // Alternate 2 runway
+	Altn2Route  string `form:"altn_2_route" json:"altn_2_route,omitempty"` // Alternate 2 routing
+	Altn3ID     string `form:"altn_3_id" json:"altn_3_id,omitempty"`       // Alternate 3 identifier
+	Altn3Runway string `form:"altn_3_rwy" json:"altn_3_rwy,omitempty"`     // Alternate 3 runway
+	Altn3Route  string `form:"altn_3_route" json:"altn_3_route,omitempty"` // Alternate 3 routing
+	Altn4ID     string `form:"altn_4_id" json:"altn_4_id,omitempty"`       // Alternate 4 identifier
+	Altn4Runway string `form:"altn_4_rwy" json:"altn_4_rwy,omitempty"`     // Alternate 4 runway
+	Altn4Route  string `form:"altn_4_route" json:"altn_4_route,omitempty"` // Alternate 4 routing
 
 	// Fuel and weight
-	FuelFactor     string  `form:"fuelfactor"`      // Fuel factor (e.g., "P00")
-	ManualZFW      float64 `form:"manualzfw"`       // Manual zero fuel weight (e.g., 40.1)
-	AddedFuel      string  `form:"addedfuel"`       // Extra fuel (e.g., "0.5", "20")
-	AddedFuelUnits string  `form:"addedfuel_units"` // Extra fuel units ("wgt" or "min")
-	ContFuelPct    string  `form:"contpct"`         // Contingency fuel (e.g., "0.05", "0.05/15")
-	ReserveFuel    int     `form:"resvrule"`        // Reserve fuel minutes (e.g., 45)
-	Cargo          float64 `form:"cargo"`           // Cargo weight (e.g., 5.0)
+	FuelFactor     string  `form:"fuelfactor" json:"fuelfactor,omitempty"`           // Fuel factor (e.g., "P00")
+	ManualZFW      float64 `form:"manualzfw" json:"manualzfw,omitempty"`             // Manual zero fuel weight (e.g., 40.1)
+	AddedFuel      string  `form:"addedfuel" json:"addedfuel,omitempty"`             // Extra fuel (e.g., "0.5", "20")
+	AddedFuelUnits string  `form:"addedfuel_units" json:"addedfuel_units,omitempty"` // Extra fuel units ("wgt" or "min")
+	ContFuelPct    string  `form:"contpct" json:"contpct,omitempty"`                 // Contingency fuel (e.g., "0.05", "0.05/15")
+	ReserveFuel    int     `form:"resvrule" json:"resvrule,omitempty"`               // Reserve fuel minutes (e.g., 45)
+	Cargo          float64 `form:"cargo" json:"cargo,omitempty"`                     // Cargo weight (e.g., 5.0)
 
 	// Taxi and runway
-	TaxiOut      int    `form:"taxiout"` // Taxi out time minutes (e.g., 10)
-	TaxiIn       int    `form:"taxiin"`  // Taxi in time minutes (e.g., 4)
-	OriginRunway string `form:"origrwy"` // Departure runway (e.g., "06L")
-	DestRunway   string `form:"destrwy"` // Arrival runway (e.g., "36R")
+	TaxiOut      *int   `form:"taxiout" json:"taxiout,omitempty"` // Taxi out time minutes (e.g., 10)
+	TaxiIn       *int   `form:"taxiin" json:"taxiin,omitempty"`   // Taxi in time minutes (e.g., 4)
+	OriginRunway string `form:"origrwy" json:"origrwy,omitempty"` // Departure runway (e.g., "06L")
+	DestRunway   string `form:"destrwy" json:"destrwy,omitempty"` // Arrival runway (e.g., "36R")
 
 	// Performance profiles
-	ClimbProfile   string `form:"climb"`   // Climb profile (e.g., "250/300/78")
-	DescentProfile string `form:"descent"` // Descent profile (e.g., "84/280/250")
-	CruiseProfile  string `form:"cruise"`  // Cruise profile ("LRC", "CI")
-	CostIndex      string `form:"civalue"` // Cost index (e.g., "25", "AUTO")
+	ClimbProfile   string `form:"climb" json:"climb,omitempty"`     // Climb profile (e.g., "250/300/78")
+	DescentProfile string `form:"descent" json:"descent,omitempty"` // Descent profile (e.g., "84/280/250")
+	CruiseProfile  string `form:"cruise" json:"cruise,omitempty"`   // Cruise profile ("LRC", "CI")
+	CostIndex      string `form:"civalue" json:"civalue,omitempty"` // Cost index (e.g., "25", "AUTO")
 
 	// Aircraft data (JSON string) - see official docs for structure
-	AircraftData *AircraftData `form:"acdata,omitempty"` // Custom aircraft data
+	AircraftData *AircraftData `form:"acdata,omitempty" json:"acdata,omitempty"` // Custom aircraft data
 
 	// ETOPS
-	ETOPSRule string `form:"etopsrule"` // ETOPS rule (e.g., "180", "207")
+	ETOPSRule string `form:"etopsrule" json:"etopsrule,omitempty"` // ETOPS rule (e.g., "180", "207")
 
 	// Custom remarks and static ID
-	ManualRemarks string `form:"manualrmk"` // Custom remarks (can include \n for line breaks)
-	StaticID      string `form:"static_id"` // Static reference ID (e.g., "ABC_12345")
+	ManualRemarks string `form:"manualrmk" json:"manualrmk,omitempty"` // Custom remarks (can include \n for line breaks)
+	StaticID      string `form:"static_id" json:"static_id,omitempty"` // Static reference ID (e.g., "ABC_12345")
 
 	// OFP Options
-	PlanFormat     string `form:"planformat"`   // Plan format (e.g., "LIDO")
-	Units          Units  `form:"units"`        // Units ("LBS" or "KGS")
-	NavLog         *bool  `form:"navlog"`       // Detailed navlog (1 or 0)
-	ETOPS          *bool  `form:"etops"`        // ETOPS planning (1 or 0)
-	StepClimbs     *bool  `form:"stepclimbs"`   // Plan stepclimbs (1 or 0)
-	RunwayAnalysis *bool  `form:"tlr"`          // Runway analysis (1 or 0)
-	NOTAMs         *bool  `form:"notams"`       // Include NOTAMs (1 or 0)
-	FIRNOTAMs      *bool  `form:"firnot"`       // FIR NOTAMs (1 or 0)
-	Maps           string `form:"maps"`         // Flight maps ("detail", "simple", "none")
-	OmitSIDs       *bool  `form:"omit_sids"`    // Disable SIDs (1 or 0)
-	OmitSTARs      *bool  `form:"omit_stars"`   // Disable STARs (1 or 0)
-	FindSIDSTAR    string `form:"find_sidstar"` // Auto-insert SID/STARs ("R" or "C")
+	PlanFormat     string `form:"planformat" json:"planformat,omitempty"`     // Plan format (e.g., "LIDO")
+	Units          Units  `form:"units" json:"units,omitempty"`               // Units ("LBS" or "KGS")
+	NavLog         *bool  `form:"navlog" json:"navlog,omitempty"`             // Detailed navlog (1 or 0)
+	ETOPS          *bool  `form:"etops" json:"etops,omitempty"`               // ETOPS planning (1 or 0)
+	StepClimbs     *bool  `form:"stepclimbs" json:"stepclimbs,omitempty"`     // Plan stepclimbs (1 or 0)
+	RunwayAnalysis *bool  `form:"tlr" json:"tlr,omitempty"`                   // Runway analysis (1 or 0)
+	NOTAMs         *bool  `form:"notams" json:"notams,omitempty"`             // Include NOTAMs (1 or 0)
+	FIRNOTAMs      *bool  `form:"firnot" json:"firnot,omitempty"`             // FIR NOTAMs (1 or 0)
+	Maps           string `form:"maps" json:"maps,omitempty"`                 // Flight maps ("detail", "simple", "none")
+	OmitSIDs       *bool  `form:"omit_sids" json:"omit_sids,omitempty"`       // Disable SIDs (1 or 0)
+	OmitSTARs      *bool  `form:"omit_stars" json:"omit_stars,omitempty"`     // Disable STARs (1 or 0)
+	FindSIDSTAR    string `form:"find_sidstar" json:"find_sidstar,omitempty"` // Auto-insert SID/STARs ("R" or "C")
+
+	// Extra holds generation parameters not modeled by this struct yet, sent verbatim.
+	// Keys that clash with a modeled parameter are rejected by Validate and never override it.
+	Extra map[string]string `form:"-" json:"extra,omitempty"`
 }
 
 // AircraftData represents custom aircraft data as JSON
@@ -144,12 +158,163 @@ func (ad *AircraftData) String() string {
 	return string(data)
 }
 
+// IsEmpty reports whether no aircraft data field is set
+func (ad *AircraftData) IsEmpty() bool {
+	return ad == nil || *ad == AircraftData{}
+}
+
+// ICAO Item 10 designators: letters valid on their own and letters that need a digit,
+// mapped to their allowed digits
+const (
+	equipmentLetters   = "ABCDFGHIKLNORSTUVWXYZ"
+	transponderLetters = "ACEHILNPSX"
+)
+
+var (
+	equipmentDigits   = map[byte]string{'E': "123", 'J': "1234567", 'M': "123", 'P': "123456789"}
+	transponderDigits = map[byte]string{'B': "12", 'U': "12", 'V': "12", 'D': "1", 'G': "1"}
+)
+
+// ValidateEquipment checks an ICAO Item 10a equipment string such as "SDE3FGHIRWY":
+// only valid designators (E1-E3, J1-J7, M1-M3 and P1-P9 need their digit), no repeats,
+// and N (no equipment) on its own
+func ValidateEquipment(equip string) error {
+	return validateItem10("equipment", equip, equipmentLetters, equipmentDigits)
+}
+
+// ValidateTransponder checks an ICAO Item 10b surveillance string such as "LB1": valid
+// designators (A, C, E, H, I, L, P, S, X, B1/B2, U1/U2, V1/V2, D1, G1), no repeats,
+// at most one SSR mode letter and N (no transponder) on its own
+func ValidateTransponder(transponder string) error {
+	if err := validateItem10("transponder", transponder, transponderLetters, transponderDigits); err != nil {
+		return err
+	}
+	modes := 0
+	for _, r := range transponder {
+		if strings.ContainsRune("ACEHILPSX", r) {
+			modes++
+		}
+	}
+	if modes > 1 {
+		return fmt.Errorf("transponder %q must contain at most one SSR mode (A, C, E, H, I, L, P, S or X)", transponder)
+	}
+	return nil
+}
+
+// validateItem10 splits an ICAO Item 10 string into designators and checks each against
+// the letters valid alone and the letters that require one of their allowed digits
+func validateItem10(field, value, letters string, digits map[byte]string) error {
+	if value == "" {
+		return fmt.Errorf("%s is empty", field)
+	}
+	seen := make(map[string]bool)
+	for i := 0; i < len(value); i++ {
+		designator := value[i : i+1]
+		if allowed, ok := digits[value[i]]; ok {
+			if i+1 >= len(value) || !strings.ContainsRune(allowed, rune(value[i+1])) {
+				return fmt.Errorf("%s %q: %s must be followed by one of %s", field, value, designator, allowed)
+			}
+			i++
+			designator = value[i-1 : i+1]
+		} else if !strings.Contains(letters, designator) {
+			return fmt.Errorf("%s %q contains invalid designator %q", field, value, designator)
+		}
+		if seen[designator] {
+			return fmt.Errorf("%s %q repeats %s", field, value, designator)
+		}
+		seen[designator] = true
+	}
+	if seen["N"] && len(seen) > 1 {
+		return fmt.Errorf("%s %q: N must be used on its own", field, value)
+	}
+	return nil
+}
+
+// CalculateTakeoffWeight estimates the takeoff weight as OEW + pax*paxWgt + cargo + fuelPlan.
+// All weights share the AircraftData convention of thousands of pounds: cargo and fuelPlan
+// must be given in thousands of pounds, while PaxWgt (pounds per passenger) is converted.
+// The ramp fuel is used as-is, so taxi burn is not subtracted.
+// If the result exceeds MTOW the weight is still returned together with ErrExceedsMTOW.
+func CalculateTakeoffWeight(data *AircraftData, paxCount int, cargo, fuelPlan float64) (float64, error) {
+	if data == nil {
+		return 0, ErrMissingAircraftData
+	}
+	if paxCount < 0 {
+		return 0, fmt.Errorf("passenger count cannot be negative")
+	}
+	if cargo < 0 || fuelPlan < 0 {
+		return 0, fmt.Errorf("cargo and fuel cannot be negative")
+	}
+
+	payload := float64(paxCount)*float64(data.PaxWgt)/1000 + cargo
+	tow := data.OEW + payload + fuelPlan
+
+	if data.MTOW > 0 && tow > data.MTOW {
+		return tow, fmt.Errorf("%w: %.3f > %.3f", ErrExceedsMTOW, tow, data.MTOW)
+	}
+
+	return tow, nil
+}
+
 // FetchRequest represents parameters for fetching existing flight plan data
 type FetchRequest struct {
 	UserID   string `form:"userid,omitempty"`    // SimBrief user ID
 	Username string `form:"username,omitempty"`  // SimBrief username
 	StaticID string `form:"static_id,omitempty"` // Static reference ID
 	JSON     bool   `form:"json,omitempty"`      // Request JSON format (default: XML)
+	APIKey   string `form:"api_key,omitempty"`   // SimBrief API key (required by some accounts)
+}
+
+// FetchOption configures a FetchRequest created by NewFetchRequest
+type FetchOption func(*FetchRequest)
+
+// WithUserID sets the SimBrief user ID
+func WithUserID(userID string) FetchOption {
+	return func(fr *FetchRequest) { fr.UserID = userID }
+}
+
+// WithUsername sets the SimBrief username
+func WithUsername(username string) FetchOption {
+	return func(fr *FetchRequest) { fr.Username = username }
+}
+
+// WithStaticID sets the static reference ID of a specific flight plan
+func WithStaticID(staticID string) FetchOption {
+	return func(fr *FetchRequest) { fr.StaticID = staticID }
+}
+
+// WithJSON requests the JSON format instead of XML
+func WithJSON() FetchOption {
+	return func(fr *FetchRequest) { fr.JSON = true }
+}
+
+// WithAPIKey sets the SimBrief API key
+func WithAPIKey(apiKey string) FetchOption {
+	return func(fr *FetchRequest) { fr.APIKey = apiKey }
+}
+
+// NewFetchRequest creates a fetch request from options and validates its identifiers
+func NewFetchRequest(opts ...FetchOption) (*FetchRequest, error) {
+	fr := &FetchRequest{}
+	for _, opt := range opts {
+		opt(fr)
+	}
+	if err := fr.Validate(); err != nil {
+		return nil, err
+	}
+	return fr, nil
+}
+
+// Validate checks that the request identifies exactly one user
+// A static ID alone is not enough, it must accompany a user identifier
+func (fr *FetchRequest) Validate() error {
+	if fr.UserID == "" && fr.Username == "" {
+		return ErrMissingUserID
+	}
+	if fr.UserID != "" && fr.Username != "" {
+		return ErrConflictingUserIDs
+	}
+	return nil
 }
 
 // ToQueryParams converts FetchRequest to URL query parameters
@@ -168,6 +333,9 @@ func (fr *FetchRequest) ToQueryParams() string {
 	if fr.JSON {
 		values.Add("json", "1")
 	}
+	if fr.APIKey != "" {
+		values.Add("api_key", fr.APIKey)
+	}
 
 	if len(values) == 0 {
 		return ""
@@ -184,6 +352,106 @@ func NewFlightPlanRequest(origin, destination, aircraft string) *FlightPlanReque
 	}
 }
 
+// LoadFlightPlanRequest reads a flight plan request previously written with Save
+func LoadFlightPlanRequest(r io.Reader) (*FlightPlanRequest, error) {
+	var req FlightPlanRequest
+	if err := json.NewDecoder(r).Decode(&req); err != nil {
+		return nil, fmt.Errorf("failed to decode flight plan request: %w", err)
+	}
+	return &req, nil
+}
+
+// Save writes the flight plan request as JSON so it can be restored with LoadFlightPlanRequest
+func (fpr *FlightPlanRequest) Save(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(fpr); err != nil {
+		return fmt.Errorf("failed to encode flight plan request: %w", err)
+	}
+	return nil
+}
+
+// Clone returns a deep copy of the request, including all pointer fields such as AircraftData
+func (fpr *FlightPlanRequest) Clone() *FlightPlanRequest {
+	clone := *fpr
+
+	v := reflect.ValueOf(&clone).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if field.Kind() == reflect.Ptr && !field.IsNil() {
+			copied := reflect.New(field.Elem().Type())
+			copied.Elem().Set(field.Elem())
+			field.Set(copied)
+		}
+	}
+
+	if fpr.Extra != nil {
+		clone.Extra = make(map[string]string, len(fpr.Extra))
+		for key, value := range fpr.Extra {
+			clone.Extra[key] = value
+		}
+	}
+
+	return &clone
+}
+
+// Merge returns a new request combining fpr with override, where every non-zero field of
+// override replaces the corresponding field of fpr. Neither input is modified.
+//
+// When both requests declare different Units, the weight fields inherited from fpr
+// (ManualZFW, Cargo and AddedFuel in weight mode) are converted to the override units.
+// AircraftData weights always use thousands of pounds and are never converted.
+// Merging an override with explicit units onto a base without units that carries weights
+// is ambiguous and returns an error.
+func (fpr *FlightPlanRequest) Merge(override *FlightPlanRequest) (*FlightPlanRequest, error) {
+	if override == nil {
+		return fpr.Clone(), nil
+	}
+
+	merged := *fpr
+	dst := reflect.ValueOf(&merged).Elem()
+	src := reflect.ValueOf(override).Elem()
+	for i := 0; i < src.NumField(); i++ {
+		if field := src.Field(i); !field.IsZero() {
+			dst.Field(i).Set(field)
+		}
+	}
+	merged = *merged.Clone()
+
+	if override.Units == "" || override.Units == fpr.Units {
+		return &merged, nil
+	}
+
+	// Weight fields that were not overridden still carry the base units
+	inheritsZFW := override.ManualZFW == 0 && fpr.ManualZFW != 0
+	inheritsCargo := override.Cargo == 0 && fpr.Cargo != 0
+	inheritsAddedFuel := override.AddedFuel == "" && fpr.AddedFuel != "" &&
+		merged.AddedFuelUnits == string(FuelUnitsWeight)
+
+	if !inheritsZFW && !inheritsCargo && !inheritsAddedFuel {
+		return &merged, nil
+	}
+	if fpr.Units == "" {
+		return nil, fmt.Errorf("cannot merge %s override onto base weights without units", override.Units)
+	}
+
+	if inheritsZFW {
+		merged.ManualZFW = ConvertWeight(fpr.ManualZFW, fpr.Units, override.Units)
+	}
+	if inheritsCargo {
+		merged.Cargo = ConvertWeight(fpr.Cargo, fpr.Units, override.Units)
+	}
+	if inheritsAddedFuel {
+		added, err := strconv.ParseFloat(fpr.AddedFuel, 64)
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert added fuel %q: %w", fpr.AddedFuel, err)
+		}
+		merged.AddedFuel = strconv.FormatFloat(ConvertWeight(added, fpr.Units, override.Units), 'f', -1, 64)
+	}
+
+	return &merged, nil
+}
+
 // ToURLValues converts the FlightPlanRequest to url.Values for form submission
 func (fpr *FlightPlanRequest) ToURLValues() url.Values {
 	values := url.Values{}
@@ -202,6 +470,13 @@ func (fpr *FlightPlanRequest) ToURLValues() url.Values {
 		}
 	}
 
+	// Helper function to add int pointer values (zero is emitted when set)
+	addIntPtr := func(key string, value *int) {
+		if value != nil {
+			values.Add(key, strconv.Itoa(*value))
+		}
+	}
+
 	// Helper function to add float values (skip if 0)
 	addFloat := func(key string, value float64) {
 		if value != 0 {
@@ -229,11 +504,11 @@ func (fpr *FlightPlanRequest) ToURLValues() url.Values {
 	addString("airline", fpr.Airline)
 	addString("fltnum", fpr.FlightNumber)
 	addString("date", fpr.Date)
-	addInt("deph", fpr.DepartureHour)
-	addInt("depm", fpr.DepartureMinute)
+	addIntPtr("deph", fpr.DepartureHour)
+	addIntPtr("depm", fpr.DepartureMinute)
 	addString("route", fpr.Route)
-	addInt("steh", fpr.ScheduledHour)
-	addInt("stem", fpr.ScheduledMinute)
+	addIntPtr("steh", fpr.ScheduledHour)
+	addIntPtr("stem", fpr.ScheduledMinute)
 
 	// Aircraft details
 	addString("reg", fpr.Registration)
@@ -246,6 +521,7 @@ func (fpr *FlightPlanRequest) ToURLValues() url.Values {
 	addString("cpt", fpr.CaptainName)
 	addString("dxname", fpr.DispatcherName)
 	addString("pid", fpr.PilotID)
+	addString("username", fpr.Username)
 
 	// Alternates and routing
 	addString("altn", fpr.Alternate)
@@ -277,8 +553,8 @@ func (fpr *FlightPlanRequest) ToURLValues() url.Values {
 	addFloat("cargo", fpr.Cargo)
 
 	// Taxi and runways
-	addInt("taxiout", fpr.TaxiOut)
-	addInt("taxiin", fpr.TaxiIn)
+	addIntPtr("taxiout", fpr.TaxiOut)
+	addIntPtr("taxiin", fpr.TaxiIn)
 	addString("origrwy", fpr.OriginRunway)
 	addString("destrwy", fpr.DestRunway)
 
@@ -289,7 +565,7 @@ func (fpr *FlightPlanRequest) ToURLValues() url.Values {
 	addString("civalue", fpr.CostIndex)
 
 	// Aircraft data
-	if fpr.AircraftData != nil {
+	if !fpr.AircraftData.IsEmpty() {
 		addString("acdata", fpr.AircraftData.String())
 	}
 
@@ -314,9 +590,391 @@ func (fpr *FlightPlanRequest) ToURLValues() url.Values {
 	addBool("omit_stars", fpr.OmitSTARs)
 	addString("find_sidstar", fpr.FindSIDSTAR)
 
+	// Extra parameters never replace a value set above
+	for key, value := range fpr.Extra {
+		if key != "" && !values.Has(key) {
+			values.Set(key, value)
+		}
+	}
+
 	return values
 }
 
+// canonicalUppercaseKeys are the parameters whose case is cosmetic (codes and identifiers)
+var canonicalUppercaseKeys = map[string]bool{
+	"orig": true, "dest": true, "type": true, "airline": true, "route": true,
+	"reg": true, "selcal": true, "callsign": true, "altn": true, "units": true,
+	"altn_1_id": true, "altn_2_id": true, "altn_3_id": true, "altn_4_id": true,
+	"altn_avoid": true, "origrwy": true, "destrwy": true, "planformat": true,
+}
+
+// CanonicalKey returns a stable SHA-256 hex key of the parameters the request would send,
+// for deduplicating equivalent requests. Zero-value fields are not sent and do not count;
+// values are trimmed, spacing inside the route is collapsed and code fields such as
+// airports, aircraft type and route are compared case-insensitively.
+func (fpr *FlightPlanRequest) CanonicalKey() string {
+	values := fpr.ToURLValues()
+	for key, list := range values {
+		for i, value := range list {
+			value = strings.TrimSpace(value)
+			if key == "route" || key == "altn_avoid" {
+				value = strings.Join(strings.Fields(value), " ")
+			}
+			if canonicalUppercaseKeys[key] {
+				value = strings.ToUpper(value)
+			}
+			list[i] = value
+		}
+	}
+	sum := sha256.Sum256([]byte(values.Encode()))
+	return hex.EncodeToString(sum[:])
+}
+
+// MaxContingencyFraction is the largest contingency fuel fraction accepted by ContingencyFuel
+const MaxContingencyFraction = 0.25
+
+// ContingencyFuel describes the contpct parameter: a fraction of trip fuel (0.05 = 5%)
+// with an optional minimum in minutes
+type ContingencyFuel struct {
+	Fraction   float64
+	MinMinutes int
+}
+
+// Validate checks that the fraction lies within (0, MaxContingencyFraction]
+func (cf ContingencyFuel) Validate() error {
+	if cf.Fraction <= 0 || cf.Fraction > MaxContingencyFraction {
+		return fmt.Errorf("contingency fraction must be between 0 and %g, got %g", MaxContingencyFraction, cf.Fraction)
+	}
+	return nil
+}
+
+// String formats the contingency as SimBrief expects it ("0.05" or "0.05/15")
+func (cf ContingencyFuel) String() string {
+	pct := strconv.FormatFloat(cf.Fraction, 'f', -1, 64)
+	if cf.MinMinutes <= 0 {
+		return pct
+	}
+	return fmt.Sprintf("%s/%d", pct, cf.MinMinutes)
+}
+
+// ParseContingencyFuel parses a contpct value, either a fraction ("0.05") or a fraction
+// with a minimum in minutes ("0.05/15")
+func ParseContingencyFuel(s string) (ContingencyFuel, error) {
+	pct, minutes, hasMinutes := strings.Cut(strings.TrimSpace(s), "/")
+	fraction, err := strconv.ParseFloat(strings.TrimSpace(pct), 64)
+	if err != nil {
+		return ContingencyFuel{}, fmt.Errorf("invalid contingency %q: fraction is not a number", s)
+	}
+	cf := ContingencyFuel{Fraction: fraction}
+	if hasMinutes {
+		if cf.MinMinutes, err = strconv.Atoi(strings.TrimSpace(minutes)); err != nil || cf.MinMinutes < 0 {
+			return ContingencyFuel{}, fmt.Errorf("invalid contingency %q: minimum minutes must be a non-negative integer", s)
+		}
+	}
+	return cf, nil
+}
+
+// FuelPolicy holds company bounds for the contingency and reserve of a request.
+// Zero values disable the corresponding check.
+type FuelPolicy struct {
+	MinContingencyPct float64 // Minimum contingency as a fraction of trip fuel (0.03 = 3%)
+	MaxContingencyPct float64 // Maximum contingency as a fraction of trip fuel
+	MinReserveMinutes int     // Minimum reserve fuel in minutes (resvrule)
+}
+
+// ValidateFuelPolicy checks the request's contingency (contpct) and reserve (resvrule)
+// against policy and reports every violation. A bound that is set requires the
+// corresponding request field, since SimBrief's default cannot be checked.
+func ValidateFuelPolicy(req *FlightPlanRequest, policy FuelPolicy) error {
+	var errs []error
+
+	if policy.MinContingencyPct > 0 || policy.MaxContingencyPct > 0 {
+		if req.ContFuelPct == "" {
+			errs = append(errs, fmt.Errorf("fuel policy requires a contingency (contpct)"))
+		} else if cf, err := ParseContingencyFuel(req.ContFuelPct); err != nil {
+			errs = append(errs, err)
+		} else {
+			if policy.MinContingencyPct > 0 && cf.Fraction < policy.MinContingencyPct {
+				errs = append(errs, fmt.Errorf("contingency %g is below the policy minimum of %g", cf.Fraction, policy.MinContingencyPct))
+			}
+			if policy.MaxContingencyPct > 0 && cf.Fraction > policy.MaxContingencyPct {
+				errs = append(errs, fmt.Errorf("contingency %g exceeds the policy maximum of %g", cf.Fraction, policy.MaxContingencyPct))
+			}
+		}
+	}
+
+	if policy.MinReserveMinutes > 0 && req.ReserveFuel < policy.MinReserveMinutes {
+		errs = append(errs, fmt.Errorf("reserve of %d minutes is below the policy minimum of %d", req.ReserveFuel, policy.MinReserveMinutes))
+	}
+
+	return errors.Join(errs...)
+}
+
+// DateLayout is the SimBrief date format, e.g. "15Jul24" (the month is case-insensitive)
+const DateLayout = "02Jan06"
+
+// ValidateDate checks that s is a real calendar date in DateLayout format
+func ValidateDate(s string) error {
+	if _, err := time.Parse(DateLayout, strings.TrimSpace(s)); err != nil {
+		return fmt.Errorf("invalid date %q, expected format like 15Jul24", s)
+	}
+	return nil
+}
+
+// DateFromISO converts an ISO 8601 date ("2024-07-15") into SimBrief's format ("15Jul24")
+func DateFromISO(iso string) (string, error) {
+	t, err := time.Parse("2006-01-02", strings.TrimSpace(iso))
+	if err != nil {
+		return "", fmt.Errorf("invalid ISO date %q: %w", iso, err)
+	}
+	return t.Format(DateLayout), nil
+}
+
+// MaxRouteLength is a conservative upper bound for the route field
+const MaxRouteLength = 1000
+
+// MaxFlightNumberLength is the maximum length of the fltnum field. An ICAO flight number
+// has up to 4 digits plus an optional operational suffix letter, which is also what
+// SimBrief's fltnum field accepts.
+const MaxFlightNumberLength = 5
+
+// ValidateFlightNumber checks that a flight number is alphanumeric and within SimBrief's length limit
+func ValidateFlightNumber(fltnum string) error {
+	if fltnum == "" {
+		return fmt.Errorf("%w: flight number is empty", ErrInvalidFlightNumber)
+	}
+	if len(fltnum) > MaxFlightNumberLength {
+		return fmt.Errorf("%w: %q exceeds %d characters", ErrInvalidFlightNumber, fltnum, MaxFlightNumberLength)
+	}
+	for _, r := range fltnum {
+		if !((r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9')) {
+			return fmt.Errorf("%w: %q contains invalid character %q", ErrInvalidFlightNumber, fltnum, r)
+		}
+	}
+	return nil
+}
+
+// ValidateRunway checks that a runway identifier is a two-digit heading from 01 to 36
+// with an optional L, C or R side, e.g. "06L" or "36"
+func ValidateRunway(rwy string) error {
+	if len(rwy) != 2 && len(rwy) != 3 {
+		return fmt.Errorf("%w: %q must be two digits with an optional L, C or R (e.g. \"06L\")", ErrInvalidRunway, rwy)
+	}
+	if rwy[0] < '0' || rwy[0] > '9' || rwy[1] < '0' || rwy[1] > '9' {
+		return fmt.Errorf("%w: %q must start with a two-digit heading (e.g. \"06L\")", ErrInvalidRunway, rwy)
+	}
+	if heading := int(rwy[0]-'0')*10 + int(rwy[1]-'0'); heading < 1 || heading > 36 {
+		return fmt.Errorf("%w: %q heading must be between 01 and 36", ErrInvalidRunway, rwy)
+	}
+	if len(rwy) == 3 && !strings.ContainsRune("LCR", rune(rwy[2])) {
+		return fmt.Errorf("%w: %q side must be L, C or R", ErrInvalidRunway, rwy)
+	}
+	return nil
+}
+
+// NormalizeFlightNumber strips leading zeros from the numeric part of a flight number and,
+// if minDigits > 0, pads it back with zeros to at least minDigits (e.g. "918" -> "0918" for 4)
+// A leading airline prefix and any trailing suffix letters are uppercased and kept around
+// the numeric part ("ba123" -> "BA0123" for 4). Input without digits is only uppercased.
+func NormalizeFlightNumber(fltnum string, minDigits int) string {
+	fltnum = strings.ToUpper(strings.TrimSpace(fltnum))
+
+	start := 0
+	for start < len(fltnum) && (fltnum[start] < '0' || fltnum[start] > '9') {
+		start++
+	}
+	if start == len(fltnum) {
+		return fltnum
+	}
+	end := start
+	for end < len(fltnum) && fltnum[end] >= '0' && fltnum[end] <= '9' {
+		end++
+	}
+	prefix, digits, suffix := fltnum[:start], strings.TrimLeft(fltnum[start:end], "0"), fltnum[end:]
+	if digits == "" {
+		digits = "0"
+	}
+
+	for len(digits) < minDigits {
+		digits = "0" + digits
+	}
+
+	return prefix + digits + suffix
+}
+
+// FlightPlanRequestFromValues reconstructs a FlightPlanRequest from form values, such as the
+// query string of a shared generate URL. It is the inverse of ToURLValues.
+// Unknown keys are copied into Extra so the request round-trips with ToURLValues, except
+// credentials and per-call keys (api_key, apicode, userid, timestamp, outputpage), which are
+// dropped so they are never saved or re-sent; malformed numbers, booleans or acdata JSON
+// return an error.
+func FlightPlanRequestFromValues(values url.Values) (*FlightPlanRequest, error) {
+	req := &FlightPlanRequest{}
+	v := reflect.ValueOf(req).Elem()
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		key := strings.Split(t.Field(i).Tag.Get("form"), ",")[0]
+		if key == "" || key == "-" || !values.Has(key) {
+			continue
+		}
+		raw := values.Get(key)
+		field := v.Field(i)
+
+		switch field.Interface().(type) {
+		case string, Units:
+			field.SetString(raw)
+		case int:
+			n, err := strconv.Atoi(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid integer for %s: %q", key, raw)
+			}
+			field.SetInt(int64(n))
+		case *int:
+			n, err := strconv.Atoi(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid integer for %s: %q", key, raw)
+			}
+			field.Set(reflect.ValueOf(&n))
+		case float64:
+			f, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number for %s: %q", key, raw)
+			}
+			field.SetFloat(f)
+		case *bool:
+			b, err := strconv.ParseBool(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid boolean for %s: %q", key, raw)
+			}
+			field.Set(reflect.ValueOf(&b))
+		case *AircraftData:
+			var data AircraftData
+			if err := json.Unmarshal([]byte(raw), &data); err != nil {
+				return nil, fmt.Errorf("invalid acdata JSON: %w", err)
+			}
+			field.Set(reflect.ValueOf(&data))
+		}
+	}
+
+	for key := range values {
+		if key == "" || IsKnownFormKey(key) || nonRequestKeys[key] {
+			continue
+		}
+		if req.Extra == nil {
+			req.Extra = make(map[string]string)
+		}
+		req.Extra[key] = values.Get(key)
+	}
+
+	return req, nil
+}
+
+// nonRequestKeys are credentials and per-call parameters found in dispatch URLs that do
+// not describe the flight, added by the client when sending instead
+var nonRequestKeys = map[string]bool{
+	"api_key": true, "apicode": true, "userid": true, "timestamp": true, "outputpage": true,
+}
+
+// knownFormKeys holds the form keys of FlightPlanRequest, built from its struct tags
+var knownFormKeys = func() map[string]bool {
+	keys := make(map[string]bool)
+	t := reflect.TypeOf(FlightPlanRequest{})
+	for i := 0; i < t.NumField(); i++ {
+		if key := strings.Split(t.Field(i).Tag.Get("form"), ",")[0]; key != "" && key != "-" {
+			keys[key] = true
+		}
+	}
+	return keys
+}()
+
+// IsKnownFormKey reports whether key is a generation parameter modeled by FlightPlanRequest
+func IsKnownFormKey(key string) bool {
+	return knownFormKeys[key]
+}
+
+// unmodeledParams are generation parameters SimBrief accepts that FlightPlanRequest does
+// not model yet; they can only be sent through Extra
+var unmodeledParams = map[string]bool{
+	"manualpayload": true, "taxifuel": true, "extrarmk": true,
+	"minfob": true, "minfob_units": true, "minfod": true, "minfod_units": true,
+	"melfuel": true, "melfuel_units": true, "atcfuel": true, "atcfuel_units": true,
+	"wxxfuel": true, "wxxfuel_units": true, "tankering": true, "tankering_units": true,
+	"flightrules": true, "flighttype": true, "cruisesub": true,
+}
+
+// IsSimBriefParam reports whether SimBrief accepts key as a generation parameter,
+// whether or not FlightPlanRequest models it
+func IsSimBriefParam(key string) bool {
+	return knownFormKeys[key] || unmodeledParams[key]
+}
+
+// NormalizeRegistration uppercases an aircraft registration and strips everything
+// except letters, digits and hyphens (e.g. " n123 xx" -> "N123XX", "g-abcd" -> "G-ABCD")
+func NormalizeRegistration(s string) string {
+	return normalizeIdentifier(s, "-")
+}
+
+// NormalizeCallsign uppercases an ATC callsign and strips everything except letters and digits
+func NormalizeCallsign(s string) string {
+	return normalizeIdentifier(s, "")
+}
+
+// NormalizeSELCAL uppercases a SELCAL code and strips separators (e.g. "ab-cd" -> "ABCD")
+func NormalizeSELCAL(s string) string {
+	return normalizeIdentifier(s, "")
+}
+
+// MaxStaticIDLength is the longest static ID produced by SanitizeStaticID. SimBrief does not
+// document a limit; 32 characters keeps IDs short enough for URLs and file names.
+const MaxStaticIDLength = 32
+
+// SanitizeStaticID makes s safe for URLs and file names: it trims and uppercases s, replaces
+// every character other than A-Z, 0-9, '_' and '-' with '_' and truncates the result to
+// MaxStaticIDLength characters (e.g. "kjfk/egll 1" -> "KJFK_EGLL_1")
+func SanitizeStaticID(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(strings.TrimSpace(s)) {
+		if b.Len() == MaxStaticIDLength {
+			break
+		}
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == '-' {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+// selcalLetters are the tone letters used in SELCAL codes (I, N and O are not used)
+const selcalLetters = "ABCDEFGHJKLMPQRS"
+
+// ValidateSELCAL checks that s normalizes to four valid SELCAL letters
+func ValidateSELCAL(s string) error {
+	code := NormalizeSELCAL(s)
+	if len(code) != 4 {
+		return fmt.Errorf("SELCAL %q must have 4 letters", s)
+	}
+	for _, r := range code {
+		if !strings.ContainsRune(selcalLetters, r) {
+			return fmt.Errorf("SELCAL %q contains invalid letter %q", s, r)
+		}
+	}
+	return nil
+}
+
+// normalizeIdentifier uppercases s and keeps only A-Z, 0-9 and the extra allowed characters
+func normalizeIdentifier(s, allowed string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(s) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || strings.ContainsRune(allowed, r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
 // Validate checks if the flight plan request has all required fields
 func (fpr *FlightPlanRequest) Validate() error {
 	if fpr.Origin == "" {
@@ -328,5 +986,20 @@ func (fpr *FlightPlanRequest) Validate() error {
 	if fpr.Aircraft == "" {
 		return ErrMissingAircraft
 	}
-	return nil
+	return fpr.ValidateExtra()
+}
+
+// ValidateExtra checks that no Extra key clashes with a parameter modeled by FlightPlanRequest
+func (fpr *FlightPlanRequest) ValidateExtra() error {
+	var conflicts []string
+	for key := range fpr.Extra {
+		if IsKnownFormKey(key) {
+			conflicts = append(conflicts, key)
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+	sort.Strings(conflicts)
+	return fmt.Errorf("%w: %s", ErrExtraParamConflict, strings.Join(conflicts, ", "))
 }
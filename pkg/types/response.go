@@ -4,6 +4,11 @@ import (
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -20,34 +25,94 @@ type FlightParams struct {
 
 // FlightPlanResponse represents the complete response from SimBrief API
 type FlightPlanResponse struct {
-	XMLName xml.Name `xml:"SimBrief" json:"-"`
+	XMLName xml.Name `xml:"OFP" json:"-"`
 
 	// Basic flight information
-	Params      FlightParams  `xml:"params" json:"params"`
-	General     GeneralInfo   `xml:"general" json:"general"`
-	Aircraft    AircraftInfo  `xml:"aircraft" json:"aircraft"`
-	Origin      AirportInfo   `xml:"origin" json:"origin"`
-	Destination AirportInfo   `xml:"destination" json:"destination"`
-	Alternate   AlternateInfo `xml:"alternate" json:"alternate"`
+	Params      FlightParams `xml:"params" json:"params"`
+	General     GeneralInfo  `xml:"general" json:"general"`
+	Aircraft    AircraftInfo `xml:"aircraft" json:"aircraft"`
+	Origin      AirportInfo  `xml:"origin" json:"origin"`
+	Destination AirportInfo  `xml:"destination" json:"destination"`
+
+	// Alternate is the primary alternate, i.e. the first entry of Alternates.
+	//
+	// Deprecated: use Alternates, which also carries any additional alternates.
+	Alternate AlternateInfo `xml:"-" json:"-"`
+
+	// Alternates lists every planned alternate in order, empty when none were planned
+	Alternates AlternateList `xml:"alternate" json:"alternate"`
+
+	// EnrouteAltns lists the enroute (ETOPS/diversion) alternates, empty when none were planned
+	EnrouteAltns AirportList `xml:"enroute_altn" json:"enroute_altn"`
 
 	// Flight planning data
 	Fuel    FuelInfo    `xml:"fuel" json:"fuel"`
 	Weights WeightInfo  `xml:"weights" json:"weights"`
 	Times   TimeInfo    `xml:"times" json:"times"`
 	Weather WeatherInfo `xml:"weather" json:"weather"`
-	NavLog  interface{} `xml:"navlog>fix" json:"navlog"`
+	NavLog  *NavLog     `xml:"navlog" json:"navlog"`
+
+	// Simulator integration metadata, empty when SimBrief does not include it
+	Sim SimInfo `xml:"sim" json:"sim"`
 
 	// Generated files and links
-	Files FilesInfo `xml:"files" json:"files"`
-	Links LinksInfo `xml:"links" json:"links"`
+	Files  FilesInfo  `xml:"files" json:"files"`
+	Images ImagesInfo `xml:"images" json:"images"`
+	Links  LinksInfo  `xml:"links" json:"links"`
 
 	// Raw response for advanced usage
 	Raw map[string]interface{} `xml:"-" json:"raw,omitempty"`
 }
 
+// UnmarshalJSON decodes the response and fills Alternate from Alternates
+func (fp *FlightPlanResponse) UnmarshalJSON(data []byte) error {
+	type plain FlightPlanResponse
+	if err := json.Unmarshal(data, (*plain)(fp)); err != nil {
+		return err
+	}
+	fp.syncAlternate()
+	return nil
+}
+
+// UnmarshalXML decodes the response and fills Alternate from Alternates
+func (fp *FlightPlanResponse) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain FlightPlanResponse
+	if err := d.DecodeElement((*plain)(fp), &start); err != nil {
+		return err
+	}
+	fp.syncAlternate()
+	return nil
+}
+
+func (fp *FlightPlanResponse) syncAlternate() {
+	if len(fp.Alternates) > 0 {
+		fp.Alternate = fp.Alternates[0]
+	}
+}
+
+// alternates returns the planned alternates, falling back to the deprecated Alternate
+// field for responses built by hand without Alternates
+func (fp *FlightPlanResponse) alternates() []AlternateInfo {
+	if len(fp.Alternates) > 0 {
+		return fp.Alternates
+	}
+	if fp.Alternate != (AlternateInfo{}) {
+		return []AlternateInfo{fp.Alternate}
+	}
+	return nil
+}
+
+// primaryAlternate returns the first planned alternate, zero when none is planned
+func (fp *FlightPlanResponse) primaryAlternate() AlternateInfo {
+	if alternates := fp.alternates(); len(alternates) > 0 {
+		return alternates[0]
+	}
+	return AlternateInfo{}
+}
+
 // StaticIDField handles the static_id field which can be either a string or an empty object
 type StaticIDField struct {
-	Value string
+	Value string `xml:",chardata"`
 }
 
 // UnmarshalJSON implements custom JSON unmarshaling for StaticIDField
@@ -97,6 +162,145 @@ type GeneralInfo struct {
 	Distance       string    `xml:"air_distance" json:"air_distance"`
 	Units          Units     `xml:"units" json:"units"`
 	CreatedTime    time.Time `xml:"plan_html" json:"plan_html"`
+	SafeAltitude   string    `xml:"enroute_safe_altitude" json:"enroute_safe_altitude"` // Enroute safe altitude (feet)
+	CruiseTAS      string    `xml:"cruise_tas" json:"cruise_tas"`                       // Planned cruise true airspeed (knots)
+	CruiseMach     string    `xml:"cruise_mach" json:"cruise_mach"`                     // Planned cruise Mach number (e.g., ".78")
+	IsETOPS        string    `xml:"is_etops" json:"is_etops"`                           // "1" when the plan was computed under ETOPS rules
+	ETOPSRule      string    `xml:"etops_rule" json:"etops_rule"`                       // ETOPS diversion time rule in minutes (e.g., "180")
+
+	// Remarks are kept apart: DispatcherNotes echoes the user's manualrmk input,
+	// SystemRemarks holds the remarks generated by SimBrief
+	DispatcherNotes RemarksField `xml:"dx_rmk" json:"dx_rmk"`
+	SystemRemarks   RemarksField `xml:"sys_rmk" json:"sys_rmk"`
+}
+
+// NavIDs splits RouteNAVID into its navaid idents, like RouteHelper.ParseRoute splits a
+// route. An empty field yields an empty, non-nil slice.
+func (g GeneralInfo) NavIDs() []string {
+	fields := strings.Fields(g.RouteNAVID)
+	if fields == nil {
+		return []string{}
+	}
+	return fields
+}
+
+// StepClimb is one step of the planned vertical profile
+type StepClimb struct {
+	Position string // Fix where the step starts, empty when not given
+	Altitude int    // Altitude in feet
+	Speed    string // ICAO speed group (e.g. "N0450" or "M078"), empty when not given
+}
+
+var (
+	stepSpeedLevelPattern = regexp.MustCompile(`^([NK]\d{4}|M\d{3})([FA])(\d{3})$`)
+	stepSpeedPattern      = regexp.MustCompile(`^([NK]\d{4}|M\d{3})$`)
+	stepLevelPattern      = regexp.MustCompile(`^FL?(\d{3})$`)
+	stepAltitudePattern   = regexp.MustCompile(`^\d{4,5}$`)
+	stepPositionPattern   = regexp.MustCompile(`^[A-Z0-9]{2,}$`)
+)
+
+// StepClimbSegments parses StepClimbs into steps. Each space-separated step combines, with
+// '/', an optional position, an altitude ("FL360", "F360", four digits in hundreds of feet
+// such as "0360" or five digits in feet) and an optional speed ("N0450", "M078"); the ICAO
+// speed/level group "N0450F360" is also accepted. An empty string yields an empty slice.
+func (g GeneralInfo) StepClimbSegments() ([]StepClimb, error) {
+	tokens := strings.Fields(strings.ToUpper(g.StepClimbs))
+	steps := make([]StepClimb, 0, len(tokens))
+	for _, token := range tokens {
+		step, err := parseStepClimb(token)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// parseStepClimb parses one "POSITION/ALTITUDE/SPEED" step, see StepClimbSegments
+func parseStepClimb(token string) (StepClimb, error) {
+	var step StepClimb
+	for _, part := range strings.Split(token, "/") {
+		switch {
+		case stepSpeedLevelPattern.MatchString(part):
+			match := stepSpeedLevelPattern.FindStringSubmatch(part)
+			step.Speed = match[1]
+			level, _ := strconv.Atoi(match[3])
+			step.Altitude = level * 100
+		case stepSpeedPattern.MatchString(part):
+			step.Speed = part
+		case stepLevelPattern.MatchString(part):
+			level, _ := strconv.Atoi(stepLevelPattern.FindStringSubmatch(part)[1])
+			step.Altitude = level * 100
+		case stepAltitudePattern.MatchString(part):
+			altitude, _ := strconv.Atoi(part)
+			if len(part) == 4 {
+				altitude *= 100
+			}
+			step.Altitude = altitude
+		case step.Position == "" && stepPositionPattern.MatchString(part):
+			step.Position = part
+		default:
+			return StepClimb{}, fmt.Errorf("invalid step climb %q: unexpected %q", token, part)
+		}
+	}
+	if step.Altitude == 0 {
+		return StepClimb{}, fmt.Errorf("invalid step climb %q: no altitude", token)
+	}
+	return step, nil
+}
+
+// RemarksField handles remark fields which can be a string, a list of lines or an empty object
+type RemarksField struct {
+	Value string
+}
+
+// UnmarshalJSON implements custom JSON unmarshaling for RemarksField
+func (r *RemarksField) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		r.Value = str
+		return nil
+	}
+
+	// Multiple remark lines are returned as an array
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err == nil {
+		r.Value = strings.Join(lines, "\n")
+		return nil
+	}
+
+	// An empty object means no remarks
+	var obj map[string]interface{}
+	if err := json.Unmarshal(data, &obj); err == nil {
+		r.Value = ""
+		return nil
+	}
+
+	return fmt.Errorf("remarks must be a string, array or object")
+}
+
+// UnmarshalXML implements custom XML unmarshaling for RemarksField
+// Repeated elements are joined with newlines
+func (r *RemarksField) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var line string
+	if err := d.DecodeElement(&line, &start); err != nil {
+		return err
+	}
+	if r.Value != "" {
+		r.Value += "\n"
+	}
+	r.Value += line
+	return nil
+}
+
+// MarshalJSON implements custom JSON marshaling for RemarksField
+func (r RemarksField) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.Value)
+}
+
+// String returns the remarks text
+func (r RemarksField) String() string {
+	return r.Value
 }
 
 // AircraftInfo contains aircraft-specific information
@@ -106,7 +310,7 @@ type AircraftInfo struct {
 	Engine       string      `xml:"engine" json:"engine"`
 	Registration string      `xml:"reg" json:"reg"`
 	Fin          string      `xml:"fin" json:"fin"`
-	SELCAL       interface{} `xml:"selcal" json:"selcal"`
+	SELCAL       SELCALField `xml:"selcal" json:"selcal"`
 	MaxPax       int         `xml:"maxpax" json:"maxpax"`
 	OEW          float64     `xml:"oew" json:"oew"`         // Operating Empty Weight
 	MZFW         float64     `xml:"mzfw" json:"mzfw"`       // Max Zero Fuel Weight
@@ -115,6 +319,52 @@ type AircraftInfo struct {
 	MaxFuel      float64     `xml:"maxfuel" json:"maxfuel"` // Max Fuel Capacity
 }
 
+// SELCALField handles the selcal field which can be either a string or an empty object
+// The code is normalized with NormalizeSELCAL ("ab-cd" -> "ABCD")
+type SELCALField struct {
+	Value string
+}
+
+// UnmarshalJSON implements custom JSON unmarshaling for SELCALField
+func (s *SELCALField) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		s.Value = NormalizeSELCAL(str)
+		return nil
+	}
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal(data, &obj); err == nil {
+		s.Value = "" // Empty object means no SELCAL
+		return nil
+	}
+
+	return fmt.Errorf("selcal must be either string or object")
+}
+
+// UnmarshalXML implements custom XML unmarshaling for SELCALField
+func (s *SELCALField) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var str string
+	if err := d.DecodeElement(&str, &start); err != nil {
+		return err
+	}
+	s.Value = NormalizeSELCAL(str)
+	return nil
+}
+
+// MarshalJSON implements custom JSON marshaling for SELCALField
+func (s SELCALField) MarshalJSON() ([]byte, error) {
+	if s.Value == "" {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(s.Value)
+}
+
+// String returns the SELCAL code
+func (s SELCALField) String() string {
+	return s.Value
+}
+
 // AirportInfo contains airport information
 type AirportInfo struct {
 	ICAO        string `xml:"icao_code" json:"icao_code"`
@@ -129,6 +379,66 @@ type AirportInfo struct {
 	Runway      string `xml:"plan_rwy" json:"plan_rwy"`
 	TimeZone    string `xml:"timezone" json:"timezone"`
 	UTCOffset   string `xml:"utc_offset" json:"utc_offset"`
+	Approach    string `xml:"approach" json:"approach"`     // Planned approach procedure (destination only)
+	Transition  string `xml:"transition" json:"transition"` // Planned approach transition (destination only)
+}
+
+// AirportList handles repeated airport entries, which SimBrief returns as a single
+// object instead of an array when there is only one
+type AirportList []AirportInfo
+
+// UnmarshalJSON implements custom JSON unmarshaling for AirportList
+func (l *AirportList) UnmarshalJSON(data []byte) error {
+	airports, err := unmarshalOneOrMany[AirportInfo](data)
+	*l = airports
+	return err
+}
+
+// EnrouteAlternates returns the enroute diversion airports planned along the route
+func (fp *FlightPlanResponse) EnrouteAlternates() []AirportInfo {
+	return fp.EnrouteAltns
+}
+
+// Codes returns the airport's ICAO and IATA codes
+func (a AirportInfo) Codes() (icao, iata string) {
+	return a.ICAO, a.IATA
+}
+
+// ElevationFeet parses the airport elevation, e.g. "13 ft", "13" or "-1266 ft", into whole feet
+func (a AirportInfo) ElevationFeet() (int, error) {
+	raw := strings.ToLower(strings.TrimSpace(a.Elevation))
+	for _, suffix := range []string{"feet", "ft"} {
+		if strings.HasSuffix(raw, suffix) {
+			raw = strings.TrimSpace(strings.TrimSuffix(raw, suffix))
+			break
+		}
+	}
+	feet, err := ParseNumber(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid elevation %q: %w", a.Elevation, err)
+	}
+	return int(math.Round(feet)), nil
+}
+
+// AirportByCode finds the origin, destination or one of the alternates by ICAO or IATA code,
+// ignoring case. Alternates only carry codes and name in the returned AirportInfo.
+func (fp *FlightPlanResponse) AirportByCode(code string) (*AirportInfo, bool) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return nil, false
+	}
+
+	airports := []*AirportInfo{&fp.Origin, &fp.Destination}
+	for _, alternate := range fp.alternates() {
+		airports = append(airports, &AirportInfo{ICAO: alternate.ICAO, IATA: alternate.IATA, Name: alternate.Name})
+	}
+	for _, airport := range airports {
+		icao, iata := airport.Codes()
+		if strings.EqualFold(icao, code) || strings.EqualFold(iata, code) {
+			return airport, true
+		}
+	}
+	return nil, false
 }
 
 // AlternateInfo contains alternate airport information
@@ -139,6 +449,44 @@ type AlternateInfo struct {
 	Distance     string `xml:"distance" json:"distance"`
 	Bearing      string `xml:"bearing" json:"bearing"`
 	FuelRequired string `xml:"burn" json:"burn"`
+
+	// Weather at the alternate as reported in the OFP, empty when absent
+	MetarCategory   string `xml:"metar_category" json:"metar_category"`     // VFR, MVFR, IFR or LIFR
+	MetarVisibility string `xml:"metar_visibility" json:"metar_visibility"` // Reported visibility
+	MetarCeiling    string `xml:"metar_ceiling" json:"metar_ceiling"`       // Reported ceiling (feet)
+}
+
+// AlternateList handles repeated alternate entries, which SimBrief returns as a single
+// object instead of an array when there is only one
+type AlternateList []AlternateInfo
+
+// UnmarshalJSON implements custom JSON unmarshaling for AlternateList
+func (l *AlternateList) UnmarshalJSON(data []byte) error {
+	alternates, err := unmarshalOneOrMany[AlternateInfo](data)
+	*l = alternates
+	return err
+}
+
+// unmarshalOneOrMany decodes a repeated SimBrief element, which is an array, a single
+// object when there is only one entry, or an empty object when there are none
+func unmarshalOneOrMany[T any](data []byte) ([]T, error) {
+	trimmed := strings.TrimSpace(string(data))
+	switch {
+	case trimmed == "" || trimmed == "null" || trimmed == "{}":
+		return nil, nil
+	case strings.HasPrefix(trimmed, "["):
+		var items []T
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, err
+		}
+		return items, nil
+	default:
+		var item T
+		if err := json.Unmarshal(data, &item); err != nil {
+			return nil, err
+		}
+		return []T{item}, nil
+	}
 }
 
 // FuelInfo contains fuel planning information
@@ -153,6 +501,7 @@ type FuelInfo struct {
 	MinTakeoff  string `xml:"min_takeoff" json:"min_takeoff"`       // Minimum takeoff fuel
 	PlanLanding string `xml:"plan_landing" json:"plan_landing"`     // Planned landing fuel
 	AvgFuelFlow string `xml:"avg_fuel_flow" json:"avg_fuel_flow"`   // Average fuel flow
+	Tankering   string `xml:"tankering" json:"tankering"`           // Recommended tankering fuel, empty when none
 }
 
 // WeightInfo contains weight and balance information
@@ -200,10 +549,12 @@ type NavLogFix struct {
 	Latitude    float64 `xml:"pos_lat" json:"pos_lat"`
 	Longitude   float64 `xml:"pos_long" json:"pos_long"`
 	Route       string  `xml:"via_airway" json:"via_airway"`
+	Stage       string  `xml:"stage" json:"stage"` // Flight phase of the leg: CLB, CRZ or DSC
 	Distance    float64 `xml:"distance_nm" json:"distance_nm"`
 	Track       float64 `xml:"track_true" json:"track_true"`
 	TrackMag    float64 `xml:"track_mag" json:"track_mag"`
 	Altitude    int     `xml:"altitude_feet" json:"altitude_feet"`
+	MORA        int     `xml:"mora" json:"mora"` // Minimum off-route / segment safe altitude (feet)
 	Wind        string  `xml:"wind" json:"wind"`
 	Temperature int     `xml:"oat" json:"oat"`
 	FuelFlow    float64 `xml:"fuel_flow" json:"fuel_flow"`
@@ -212,6 +563,13 @@ type NavLogFix struct {
 	ETA         string  `xml:"eta" json:"eta"`
 }
 
+// SimInfo contains simulator integration metadata describing the plan's target simulator
+type SimInfo struct {
+	Simulator string `xml:"simulator" json:"simulator"` // Target simulator (e.g., "MSFS", "XP12")
+	Version   string `xml:"version" json:"version"`     // Simulator version, when known
+	Scenario  string `xml:"scenario" json:"scenario"`   // Scenario or flight file name
+}
+
 // FilesInfo contains links to generated files
 type FilesInfo struct {
 	Directory string      `xml:"directory" json:"directory"`
@@ -224,6 +582,113 @@ type FilesInfo struct {
 	XPFMSLink interface{} `xml:"xpfms" json:"xpfms"`
 }
 
+// UnmarshalXML implements custom XML unmarshaling for FilesInfo
+// File links are stored in the same shape as the JSON response: a map with "name" and "link"
+func (f *FilesInfo) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type fileLink struct {
+		Name string `xml:"name"`
+		Link string `xml:"link"`
+	}
+	var files struct {
+		Directory string    `xml:"directory"`
+		PDF       *fileLink `xml:"pdf"`
+		XML       *fileLink `xml:"xml"`
+		JSON      *fileLink `xml:"json"`
+		KML       *fileLink `xml:"kml"`
+		PLN       *fileLink `xml:"pln"`
+		FMS       *fileLink `xml:"fms"`
+		XPFMS     *fileLink `xml:"xpfms"`
+	}
+	if err := d.DecodeElement(&files, &start); err != nil {
+		return err
+	}
+
+	toField := func(link *fileLink) interface{} {
+		if link == nil {
+			return nil
+		}
+		return map[string]interface{}{"name": link.Name, "link": link.Link}
+	}
+
+	*f = FilesInfo{
+		Directory: files.Directory,
+		PDFLink:   toField(files.PDF),
+		XMLLink:   toField(files.XML),
+		JSONLink:  toField(files.JSON),
+		KMLLink:   toField(files.KML),
+		PLNLink:   toField(files.PLN),
+		FMSLink:   toField(files.FMS),
+		XPFMSLink: toField(files.XPFMS),
+	}
+	return nil
+}
+
+// KMLURL returns the absolute URL of the generated KML file
+// The link may be a plain file name or an object with a "link" key; false/empty means no file
+func (f FilesInfo) KMLURL() (string, bool) {
+	return f.fileURL(f.KMLLink)
+}
+
+// fileURL resolves a file link field against the files directory
+func (f FilesInfo) fileURL(field interface{}) (string, bool) {
+	var link string
+	switch v := field.(type) {
+	case string:
+		link = v
+	case map[string]interface{}:
+		link, _ = v["link"].(string)
+	}
+
+	link = strings.TrimSpace(link)
+	if link == "" {
+		return "", false
+	}
+	if strings.HasPrefix(link, "http://") || strings.HasPrefix(link, "https://") {
+		return link, true
+	}
+	return f.Directory + link, true
+}
+
+// ImagesInfo contains the map images generated for the OFP
+// Maps is empty when maps were not requested (maps=none)
+type ImagesInfo struct {
+	Directory string  `xml:"directory" json:"directory"`
+	Maps      MapList `xml:"map" json:"map"`
+}
+
+// MapList handles repeated map entries, which SimBrief returns as a single object
+// when there is only one map and as an empty object when there are none
+type MapList []MapImage
+
+// UnmarshalJSON implements custom JSON unmarshaling for MapList
+func (l *MapList) UnmarshalJSON(data []byte) error {
+	maps, err := unmarshalOneOrMany[MapImage](data)
+	*l = maps
+	return err
+}
+
+// MapImage represents a single generated map image
+type MapImage struct {
+	Name string `xml:"name" json:"name"`
+	Link string `xml:"link" json:"link"`
+}
+
+// URL returns the absolute URL of the map image, resolving the link against directory
+func (m MapImage) URL(directory string) string {
+	if strings.HasPrefix(m.Link, "http://") || strings.HasPrefix(m.Link, "https://") {
+		return m.Link
+	}
+	return directory + m.Link
+}
+
+// DownloadedMap contains the raw content of a downloaded map image
+type DownloadedMap struct {
+	Name        string
+	URL         string
+	ContentType string
+	Data        []byte
+}
+
 // LinksInfo contains various SimBrief links
 type LinksInfo struct {
 	SkyVectorLink  string `xml:"skyvector" json:"skyvector"`
@@ -242,6 +707,27 @@ func (e APIError) Error() string {
 	return e.Message
 }
 
+// UnmarshalJSON accepts both {"message": ..., "code": ...} and the fetcher's
+// {"fetch": {"status": ...}} payload, taking the message from the fetch status
+func (e *APIError) UnmarshalJSON(data []byte) error {
+	var payload struct {
+		Message string `json:"message"`
+		Code    int    `json:"code"`
+		Fetch   struct {
+			Status string `json:"status"`
+		} `json:"fetch"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return err
+	}
+
+	e.Message, e.Code = payload.Message, payload.Code
+	if e.Message == "" {
+		e.Message = payload.Fetch.Status
+	}
+	return nil
+}
+
 // SupportedOptions represents the response from the inputs.list endpoint
 // Based on official SimBrief API documentation at http://www.simbrief.com/api/inputs.list.json
 type SupportedOptions struct {
@@ -271,3 +757,45 @@ type LayoutOption struct {
 	PopularityPct float64 `json:"popularity_pct"`
 	LastUpdated   string  `json:"last_updated"`
 }
+
+// TopAircraft returns the n most popular aircraft sorted by PopularityPct descending,
+// ties broken by ID. Fewer are returned when the list is shorter; n <= 0 returns none.
+func (o *SupportedOptions) TopAircraft(n int) []AircraftOption {
+	aircraft := make([]AircraftOption, 0, len(o.Aircraft))
+	for _, option := range o.Aircraft {
+		aircraft = append(aircraft, option)
+	}
+	sort.Slice(aircraft, func(i, j int) bool {
+		if aircraft[i].PopularityPct != aircraft[j].PopularityPct {
+			return aircraft[i].PopularityPct > aircraft[j].PopularityPct
+		}
+		return aircraft[i].ID < aircraft[j].ID
+	})
+	return aircraft[:clampTop(n, len(aircraft))]
+}
+
+// TopLayouts returns the n most popular layouts, ordered like TopAircraft
+func (o *SupportedOptions) TopLayouts(n int) []LayoutOption {
+	layouts := make([]LayoutOption, 0, len(o.Layouts))
+	for _, option := range o.Layouts {
+		layouts = append(layouts, option)
+	}
+	sort.Slice(layouts, func(i, j int) bool {
+		if layouts[i].PopularityPct != layouts[j].PopularityPct {
+			return layouts[i].PopularityPct > layouts[j].PopularityPct
+		}
+		return layouts[i].ID < layouts[j].ID
+	})
+	return layouts[:clampTop(n, len(layouts))]
+}
+
+// clampTop bounds a requested count to [0, length]
+func clampTop(n, length int) int {
+	if n < 0 {
+		return 0
+	}
+	if n > length {
+		return length
+	}
+	return n
+}
@@ -0,0 +1,138 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FlightSummary is a flat digest of the most used flight plan values
+type FlightSummary struct {
+	Origin      string
+	Destination string
+	Aircraft    string
+	Route       string
+	DistanceNM  float64
+	BlockTime   time.Duration
+	RampFuel    float64
+	PaxCount    int
+	Units       Units
+
+	// Warnings lists the values that could not be parsed and were left at zero
+	Warnings []string
+}
+
+// Summary returns a compact digest of the flight plan
+// Values that fail to parse are left at zero and reported in Warnings
+func (fp *FlightPlanResponse) Summary() FlightSummary {
+	summary := FlightSummary{
+		Origin:      fp.Origin.ICAO,
+		Destination: fp.Destination.ICAO,
+		Aircraft:    fp.Aircraft.ICAO,
+		Route:       fp.General.Route,
+		Units:       fp.General.Units,
+	}
+
+	warn := func(field string, err error) {
+		summary.Warnings = append(summary.Warnings, fmt.Sprintf("%s: %v", field, err))
+	}
+
+	if distance, err := ParseNumber(fp.General.Distance); err != nil {
+		warn("distance", err)
+	} else {
+		summary.DistanceNM = distance
+	}
+
+	if block, err := parseDuration(fp.Times.BlockTime); err != nil {
+		warn("block time", err)
+	} else {
+		summary.BlockTime = block
+	}
+
+	if ramp, err := ParseNumber(fp.Fuel.Plan); err != nil {
+		warn("ramp fuel", err)
+	} else {
+		summary.RampFuel = ramp
+	}
+
+	if pax, err := ParseNumber(fp.Weights.PaxCount); err != nil {
+		warn("pax count", err)
+	} else {
+		summary.PaxCount = int(pax)
+	}
+
+	return summary
+}
+
+// ChatSummaryMaxLength is the character budget of ChatSummary
+const ChatSummaryMaxLength = 200
+
+// ChatSummary returns a one-line summary for pasting into chat, e.g.
+// "KJFK → EGLL | B39M | 3004 nm | block 6h52m | fuel 45000 kgs"
+// Values that are missing or fail to parse are omitted. The result is truncated
+// to ChatSummaryMaxLength characters.
+func (fp *FlightPlanResponse) ChatSummary() string {
+	summary := fp.Summary()
+
+	parts := []string{fmt.Sprintf("%s → %s", summary.Origin, summary.Destination)}
+	if summary.Aircraft != "" {
+		parts = append(parts, summary.Aircraft)
+	}
+	if summary.DistanceNM > 0 {
+		parts = append(parts, fmt.Sprintf("%.0f nm", summary.DistanceNM))
+	}
+	if block := summary.BlockTime.Round(time.Minute); block > 0 {
+		parts = append(parts, fmt.Sprintf("block %dh%02dm", int(block.Hours()), int(block.Minutes())%60))
+	}
+	if summary.RampFuel > 0 {
+		fuel := fmt.Sprintf("fuel %.0f", summary.RampFuel)
+		if units, err := fp.PlanUnits(); err == nil {
+			fuel += " " + strings.ToLower(string(units))
+		}
+		parts = append(parts, fuel)
+	}
+
+	line := strings.Join(parts, " | ")
+	if runes := []rune(line); len(runes) > ChatSummaryMaxLength {
+		line = string(runes[:ChatSummaryMaxLength-1]) + "…"
+	}
+	return line
+}
+
+// summaryPayload is the fixed schema written by MarshalSummaryJSON
+// Fields must only ever be added, never renamed or removed
+type summaryPayload struct {
+	Origin       string  `json:"origin"`
+	Destination  string  `json:"destination"`
+	Aircraft     string  `json:"aircraft"`
+	Route        string  `json:"route"`
+	BlockMinutes int     `json:"block_time_min"`
+	RampFuel     float64 `json:"ramp_fuel"`
+	Units        string  `json:"units"`
+	Pax          int     `json:"pax"`
+}
+
+// MarshalSummaryJSON encodes a compact, stable JSON digest of the plan for webhooks:
+// origin, destination, aircraft, route, block time (minutes), ramp fuel with its units
+// and pax count. The schema does not follow changes in the SimBrief response shape;
+// values that fail to parse are written as zero.
+func (fp *FlightPlanResponse) MarshalSummaryJSON() ([]byte, error) {
+	summary := fp.Summary()
+
+	units := ""
+	if planUnits, err := fp.PlanUnits(); err == nil {
+		units = strings.ToLower(string(planUnits))
+	}
+
+	return json.Marshal(summaryPayload{
+		Origin:       summary.Origin,
+		Destination:  summary.Destination,
+		Aircraft:     summary.Aircraft,
+		Route:        summary.Route,
+		BlockMinutes: int(summary.BlockTime / time.Minute),
+		RampFuel:     summary.RampFuel,
+		Units:        units,
+		Pax:          summary.PaxCount,
+	})
+}